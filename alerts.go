@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func handlerAlert(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) < 1 {
+		return usageError("alert command expects a subcommand - add <query>, list or remove <alert-id>")
+	}
+
+	switch cmd.arguments[0] {
+	case "add":
+		if len(cmd.arguments) < 2 {
+			return usageError("there should be at least one argument for alert add command - search query")
+		}
+
+		alert, err := s.db.CreateAlert(context.Background(), database.CreateAlertParams{
+			ID: uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID: currentUser.ID,
+			Query: strings.Join(cmd.arguments[1:], " "),
+		})
+		if err != nil {
+			slog.Error("error while saving alert", "error", err)
+
+			return err
+		}
+
+		fmt.Printf("successfully added alert \"%s\" [%s]\n", alert.Query, alert.ID)
+	case "list":
+		alerts, err := s.db.GetAlertsForUser(context.Background(), currentUser.ID)
+		if err != nil {
+			slog.Error("error while retrieving alerts", "error", err)
+
+			return err
+		}
+
+		for _, alert := range alerts {
+			fmt.Printf("* \"%s\" [%s]\n", alert.Query, alert.ID)
+		}
+	case "remove":
+		if len(cmd.arguments) != 2 {
+			return usageError("there should be one argument for alert remove command - id of the alert")
+		}
+
+		alertID, err := uuid.Parse(cmd.arguments[1])
+		if err != nil {
+			slog.Error("error while parsing argument as alert id", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		removed, err := s.db.RemoveAlert(context.Background(), database.RemoveAlertParams{
+			ID: alertID,
+			UserID: currentUser.ID,
+		})
+		if err != nil {
+			slog.Error("error while removing alert", "error", err)
+
+			return err
+		}
+
+		if removed == 0 {
+			return notFoundError("no such alert")
+		}
+
+		fmt.Println("successfull alert remove")
+	default:
+		return usageError("no such alert subcommand - %s", cmd.arguments[0])
+	}
+
+	return nil
+}
+
+func handlerAlerts(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("alerts", flag.ContinueOnError)
+	limit := flags.Int("limit", 20, "maximum number of alert hits to show")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 || *limit <= 0 {
+		return usageError("alerts command accepts only positive --limit")
+	}
+
+	hits, err := s.db.GetAlertHitsForUser(context.Background(), database.GetAlertHitsForUserParams{
+		UserID: currentUser.ID,
+		Limit: int32(*limit),
+	})
+	if err != nil {
+		slog.Error("error while retrieving alert hits", "error", err)
+
+		return err
+	}
+
+	for _, hit := range hits {
+		fmt.Printf("[%s] \"%s\" matched post \"%s\" [%s] from %s\n", hit.CreatedAt.Format(time.DateTime), hit.Query, hit.Title.String, hit.PostID, hit.FeedName)
+		fmt.Printf("\t%s\n", hit.Url)
+	}
+
+	return nil
+}
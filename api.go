@@ -0,0 +1,445 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"internal/database"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type apiUser struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type apiFeed struct {
+	ID uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Name string `json:"name"`
+	URL string `json:"url"`
+	UserID uuid.UUID `json:"user_id"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+type apiFollow struct {
+	FeedID uuid.UUID `json:"feed_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Name string `json:"name"`
+	URL string `json:"url"`
+	Folder string `json:"folder,omitempty"`
+	Priority int32 `json:"priority"`
+	Notify bool `json:"notify"`
+	Hidden bool `json:"hidden"`
+}
+
+type apiPost struct {
+	ID uuid.UUID `json:"id"`
+	Title string `json:"title"`
+	URL string `json:"url"`
+	Description string `json:"description"`
+	Author string `json:"author,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	FeedID uuid.UUID `json:"feed_id"`
+	FeedName string `json:"feed_name,omitempty"`
+}
+
+func feedToAPI(feed database.Feed) apiFeed {
+	result := apiFeed{
+		ID: feed.ID,
+		CreatedAt: feed.CreatedAt,
+		Name: feed.Name,
+		URL: feed.Url,
+		UserID: feed.UserID,
+	}
+
+	if feed.LastFetchedAt.Valid {
+		result.LastFetchedAt = &feed.LastFetchedAt.Time
+	}
+
+	return result
+}
+
+func postToAPI(post database.Post, feedName string) apiPost {
+	result := apiPost{
+		ID: post.ID,
+		Title: post.Title.String,
+		URL: post.Url,
+		Description: post.Description.String,
+		Author: post.Author.String,
+		PublishedAt: post.PublishedAt,
+		FeedID: post.FeedID,
+		FeedName: feedName,
+	}
+
+	if post.Categories.Valid {
+		result.Categories = strings.Split(post.Categories.String, ", ")
+	}
+
+	return result
+}
+
+func apiGetMe(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	respondWithJSON(w, http.StatusOK, apiUser{ Name: user.Name, Role: user.Role })
+
+	return nil
+}
+
+func apiGetUsers(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	users, err := s.db.GetUsers(r.Context())
+	if err != nil {
+		return err
+	}
+
+	result := []apiUser{}
+	for _, u := range users {
+		result = append(result, apiUser{ Name: u.Name, Role: u.Role })
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+
+	return nil
+}
+
+func apiGetFeeds(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	feeds, err := s.db.GetFeeds(r.Context())
+	if err != nil {
+		return err
+	}
+
+	result := []apiFeed{}
+	for _, feed := range feeds {
+		result = append(result, feedToAPI(feed))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+
+	return nil
+}
+
+func apiCreateFeed(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	var params struct {
+		Name string `json:"name"`
+		URL string `json:"url"`
+	}
+
+	err := decodeJSON(r, &params)
+	if err != nil {
+		return err
+	}
+
+	if params.Name == "" || params.URL == "" {
+		return usageError("both name and url of the feed are required")
+	}
+
+	feed, err := s.db.CreateFeed(r.Context(), database.CreateFeedParams{
+		ID: uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name: params.Name,
+		Url: params.URL,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID: uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	respondWithJSON(w, http.StatusCreated, feedToAPI(feed))
+
+	return nil
+}
+
+func apiGetFollows(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	follows, err := s.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	result := []apiFollow{}
+	for _, follow := range follows {
+		result = append(result, apiFollow{
+			FeedID: follow.FeedID,
+			CreatedAt: follow.CreatedAt,
+			Name: follow.DisplayName,
+			URL: follow.FeedUrl,
+			Folder: follow.FolderName.String,
+			Priority: follow.Priority,
+			Notify: follow.Notify,
+			Hidden: follow.Hidden,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+
+	return nil
+}
+
+func apiCreateFollow(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	var params struct {
+		URL string `json:"url"`
+	}
+
+	err := decodeJSON(r, &params)
+	if err != nil {
+		return err
+	}
+
+	feed, err := s.db.GetFeedByURL(r.Context(), params.URL)
+	if err == sql.ErrNoRows {
+		return notFoundError("no feed with url %s, add it first", params.URL)
+	}
+	if err != nil {
+		return err
+	}
+
+	follow, err := s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID: uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	respondWithJSON(w, http.StatusCreated, apiFollow{
+		FeedID: feed.ID,
+		CreatedAt: follow.CreatedAt,
+		Name: feed.Name,
+		URL: feed.Url,
+		Notify: true,
+	})
+
+	return nil
+}
+
+func apiDeleteFollow(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	feedID, err := pathID(r, "feedID")
+	if err != nil {
+		return err
+	}
+
+	err = s.db.RemoveFeedFollowsForUser(r.Context(), database.RemoveFeedFollowsForUserParams{
+		FeedID: feedID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// apiGetPosts accepts the same filters as browse command as query parameters
+func apiGetPosts(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	query := r.URL.Query()
+
+	params := database.GetPostsForUserParams{
+		UserID: user.ID,
+		FeedUrl: sql.NullString{ String: query.Get("feed"), Valid: query.Get("feed") != "" },
+		UnreadOnly: query.Get("unread") == "true",
+		Tag: sql.NullString{ String: query.Get("tag"), Valid: query.Get("tag") != "" },
+		Folder: sql.NullString{ String: query.Get("folder"), Valid: query.Get("folder") != "" },
+		OldestFirst: query.Get("sort") == "oldest",
+		PostLimit: 20,
+	}
+
+	if sortOrder := query.Get("sort"); sortOrder != "" && sortOrder != "newest" && sortOrder != "oldest" {
+		return usageError("sort should be either newest or oldest")
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > 1000 {
+			return usageError("limit should be a number between 1 and 1000")
+		}
+
+		params.PostLimit = int32(value)
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return usageError("offset should be a non negative number")
+		}
+
+		params.PostOffset = int32(value)
+	}
+
+	if since := query.Get("since"); since != "" {
+		sinceDate, err := time.Parse(time.DateOnly, since)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		params.Since = sql.NullTime{ Time: sinceDate, Valid: true }
+	}
+
+	if until := query.Get("until"); until != "" {
+		untilDate, err := time.Parse(time.DateOnly, until)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		params.Until = sql.NullTime{ Time: untilDate, Valid: true }
+	}
+
+	posts, err := s.db.GetPostsForUser(r.Context(), params)
+	if err != nil {
+		return err
+	}
+
+	result := []apiPost{}
+	for _, post := range posts {
+		result = append(result, postToAPI(database.Post{
+			ID: post.ID,
+			Title: post.Title,
+			Url: post.Url,
+			Description: post.Description,
+			PublishedAt: post.PublishedAt,
+			FeedID: post.FeedID,
+			Author: post.Author,
+			Categories: post.Categories,
+		}, post.FeedName))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+
+	return nil
+}
+
+func apiGetPost(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	postID, err := pathID(r, "postID")
+	if err != nil {
+		return err
+	}
+
+	post, err := s.db.GetPostByIdForUser(r.Context(), database.GetPostByIdForUserParams{
+		ID: postID,
+		UserID: user.ID,
+	})
+	if err == sql.ErrNoRows {
+		return notFoundError("no such post")
+	}
+	if err != nil {
+		return err
+	}
+
+	respondWithJSON(w, http.StatusOK, postToAPI(post, ""))
+
+	return nil
+}
+
+func apiReadPost(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	postID, err := pathID(r, "postID")
+	if err != nil {
+		return err
+	}
+
+	post, err := s.db.GetPostByIdForUser(r.Context(), database.GetPostByIdForUserParams{
+		ID: postID,
+		UserID: user.ID,
+	})
+	if err == sql.ErrNoRows {
+		return notFoundError("no such post")
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		ReadAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func apiStarPost(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	postID, err := pathID(r, "postID")
+	if err != nil {
+		return err
+	}
+
+	post, err := s.db.GetPostByIdForUser(r.Context(), database.GetPostByIdForUserParams{
+		ID: postID,
+		UserID: user.ID,
+	})
+	if err == sql.ErrNoRows {
+		return notFoundError("no such post")
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.SavePost(r.Context(), database.SavePostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func apiUnstarPost(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	postID, err := pathID(r, "postID")
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.db.RemoveSavedPost(r.Context(), database.RemoveSavedPostParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return notFoundError("post is not starred")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func pathID(r *http.Request, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		return uuid.UUID{}, usageError("%s is not a valid id", name)
+	}
+
+	return id, nil
+}
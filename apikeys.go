@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func handlerAPIKey(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) < 1 {
+		return usageError("apikey command expects a subcommand - create <name>, list or revoke <key-id>")
+	}
+
+	switch cmd.arguments[0] {
+	case "create":
+		if len(cmd.arguments) < 2 {
+			return usageError("there should be at least one argument for apikey create command - name of the key")
+		}
+
+		key, err := newToken()
+		if err != nil {
+			return err
+		}
+
+		apiKey, err := s.db.CreateAPIKey(context.Background(), database.CreateAPIKeyParams{
+			ID: uuid.New(),
+			CreatedAt: time.Now(),
+			UserID: currentUser.ID,
+			Name: strings.Join(cmd.arguments[1:], " "),
+			KeyHash: hashToken(key),
+		})
+		if err != nil {
+			slog.Error("error while saving api key", "error", err)
+
+			return err
+		}
+
+		// only the hash is stored, so the key can't be shown again later
+		fmt.Printf("successfully created api key \"%s\" [%s]:\n", apiKey.Name, apiKey.ID)
+		fmt.Printf("\t%s\n", key)
+	case "list":
+		apiKeys, err := s.db.GetAPIKeysForUser(context.Background(), currentUser.ID)
+		if err != nil {
+			slog.Error("error while retrieving api keys", "error", err)
+
+			return err
+		}
+
+		for _, apiKey := range apiKeys {
+			lastUsed := "never used"
+			if apiKey.LastUsedAt.Valid {
+				lastUsed = "last used " + apiKey.LastUsedAt.Time.Format(time.DateTime)
+			}
+
+			fmt.Printf("* \"%s\" [%s], %s\n", apiKey.Name, apiKey.ID, lastUsed)
+		}
+	case "revoke":
+		if len(cmd.arguments) != 2 {
+			return usageError("there should be one argument for apikey revoke command - id of the key")
+		}
+
+		keyID, err := uuid.Parse(cmd.arguments[1])
+		if err != nil {
+			slog.Error("error while parsing argument as api key id", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		removed, err := s.db.RemoveAPIKey(context.Background(), database.RemoveAPIKeyParams{
+			ID: keyID,
+			UserID: currentUser.ID,
+		})
+		if err != nil {
+			slog.Error("error while revoking api key", "error", err)
+
+			return err
+		}
+
+		if removed == 0 {
+			return notFoundError("no such api key")
+		}
+
+		fmt.Println("successfull api key revoke")
+	default:
+		return usageError("no such apikey subcommand - %s", cmd.arguments[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// middlewareAudit records the command into audit log after it has run, whether it succeeded or not
+func middlewareAudit(handler func(*state, command) error) func(*state, command) error {
+	return func(s *state, cmd command) error {
+		// user is looked up before and after the command as it may log in or out
+		user, userErr := getSessionUser(s)
+
+		err := handler(s, cmd)
+
+		if userErr != nil {
+			user, userErr = getSessionUser(s)
+		}
+
+		var actor *database.User
+		if userErr == nil {
+			actor = &user
+		}
+
+		recordAudit(s, actor, cmd.name, fmt.Sprintf("%q", cmd.arguments), err)
+
+		return err
+	}
+}
+
+// middlewareAuditRequest records requests changing data of the user, the
+// endpoint pattern takes place of the command name
+func middlewareAuditRequest(handler userHandler) userHandler {
+	return func(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+		err := handler(s, w, r, user)
+
+		recordAudit(s, &user, r.Pattern, r.URL.Path, err)
+
+		return err
+	}
+}
+
+// recordAudit writes an entry of the audit log, user is nil when nobody is logged in
+func recordAudit(s *state, user *database.User, command string, arguments string, err error) {
+	entry := database.CreateAuditLogEntryParams{
+		ID: uuid.New(),
+		CreatedAt: time.Now(),
+		Command: command,
+		Arguments: arguments,
+		Outcome: "success",
+	}
+
+	if user != nil {
+		entry.UserID = uuid.NullUUID{ UUID: user.ID, Valid: true }
+		entry.UserName = sql.NullString{ String: user.Name, Valid: true }
+	}
+
+	if err != nil {
+		entry.Outcome = "failure"
+		entry.Error = sql.NullString{ String: err.Error(), Valid: true }
+	}
+
+	// written even when the request was cancelled, it may have changed data already
+	auditErr := s.db.CreateAuditLogEntry(context.Background(), entry)
+	if auditErr != nil {
+		slog.Error("error while writing audit log", "command", command, "error", auditErr)
+	}
+}
+
+func handlerAudit(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	limit := flags.Int("limit", 50, "maximum number of entries to show")
+	userName := flags.String("user", "", "show only commands run by this user")
+	action := flags.String("action", "", "show only this command")
+	since := flags.String("since", "", "show only commands run on or after this date (YYYY-MM-DD)")
+	until := flags.String("until", "", "show only commands run before this date (YYYY-MM-DD)")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 || *limit <= 0 {
+		return usageError("audit command accepts only --limit, --user, --action, --since and --until flags")
+	}
+
+	params := database.GetAuditLogParams{
+		UserName: sql.NullString{ String: *userName, Valid: *userName != "" },
+		Command: sql.NullString{ String: *action, Valid: *action != "" },
+		EntryLimit: int32(*limit),
+	}
+
+	if *since != "" {
+		sinceDate, err := time.Parse(time.DateOnly, *since)
+		if err != nil {
+			slog.Error("error while parsing --since as date", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		params.Since = sql.NullTime{ Time: sinceDate, Valid: true }
+	}
+
+	if *until != "" {
+		untilDate, err := time.Parse(time.DateOnly, *until)
+		if err != nil {
+			slog.Error("error while parsing --until as date", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		params.Until = sql.NullTime{ Time: untilDate, Valid: true }
+	}
+
+	entries, err := s.db.GetAuditLog(context.Background(), params)
+	if err != nil {
+		slog.Error("error while retrieving audit log", "error", err)
+
+		return err
+	}
+
+	for _, entry := range entries {
+		userName := "anonymous"
+		if entry.UserName.Valid {
+			userName = entry.UserName.String
+		}
+
+		msg := fmt.Sprintf("[%s] %s: %s %s - %s", entry.CreatedAt.Format(time.DateTime), userName, entry.Command, entry.Arguments, entry.Outcome)
+
+		if entry.Error.Valid {
+			msg = fmt.Sprintf("%s (%s)", msg, entry.Error.String)
+		}
+
+		fmt.Println(msg)
+	}
+
+	return nil
+}
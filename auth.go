@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/term"
+)
+
+const (
+	sessionDuration = 30 * 24 * time.Hour
+	passwordResetDuration = 24 * time.Hour
+)
+
+func readPassword(prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		// allow piping password in scripts
+		password, err := stdin.ReadString('\n')
+		if err != nil && password == "" {
+			return "", usageError("error while reading password")
+		}
+
+		return strings.TrimRight(password, "\r\n"), nil
+	}
+
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", usageError("error while reading password")
+	}
+
+	return string(password), nil
+}
+
+func readNewPassword() (string, error) {
+	password, err := readPassword("New password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if len(password) < 8 {
+		return "", usageError("password should be at least 8 characters long")
+	}
+
+	repeated, err := readPassword("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if password != repeated {
+		return "", usageError("passwords don't match")
+	}
+
+	return password, nil
+}
+
+func hashPassword(password string) (sql.NullString, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{ String: string(hash), Valid: true }, nil
+}
+
+// newToken generates random token used for sessions and api keys
+func newToken() (string, error) {
+	tokenBytes := make([]byte, 32)
+
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(tokenBytes), nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// createSession creates a session for the user and returns its token,
+// only the hash of the token is kept in database
+func createSession(ctx context.Context, s *state, user database.User) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.db.CreateSession(ctx, database.CreateSessionParams{
+		TokenHash: hashToken(token),
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(sessionDuration),
+		UserID: user.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// startSession creates a session for the user and stores its token in config
+func startSession(s *state, user database.User) error {
+	token, err := createSession(context.Background(), s, user)
+	if err != nil {
+		slog.Error("error while creating session", "error", err)
+
+		return err
+	}
+
+	return s.cfg.SetSession(token)
+}
+
+func getSessionUser(s *state) (database.User, error) {
+	if s.cfg.SessionToken == "" {
+		return database.User{}, notAllowedError("you are not logged in")
+	}
+
+	user, err := s.db.GetUserBySession(context.Background(), database.GetUserBySessionParams{
+		TokenHash: hashToken(s.cfg.SessionToken),
+		ExpiresAt: time.Now(),
+	})
+	if err == sql.ErrNoRows {
+		return database.User{}, notAllowedError("your session has expired, login again")
+	}
+
+	return user, err
+}
+
+func handlerLogout(s *state, cmd command) error {
+	if len(cmd.arguments) != 0 {
+		return usageError("there shouldn't be any arguments for logout command")
+	}
+
+	if s.cfg.SessionToken != "" {
+		err := s.db.DeleteSession(context.Background(), hashToken(s.cfg.SessionToken))
+		if err != nil {
+			slog.Error("error while removing session", "error", err)
+
+			return err
+		}
+	}
+
+	err := s.cfg.SetSession("")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("successfull logout")
+
+	return nil
+}
+
+func handlerPasswd(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 0 {
+		return usageError("there shouldn't be any arguments for passwd command")
+	}
+
+	password, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+		PasswordHash: passwordHash,
+		UpdatedAt: time.Now(),
+		ID: currentUser.ID,
+	})
+	if err != nil {
+		slog.Error("error while changing password", "error", err)
+
+		return err
+	}
+
+	fmt.Println("successfull password change")
+
+	return nil
+}
+
+// handlerResetPassword gives out one-time token the user sets a new password
+// with, until some admin has a password anybody can get one for an admin as
+// nobody could log in to do it otherwise
+func handlerResetPassword(s *state, cmd command) error {
+	if len(cmd.arguments) != 1 {
+		return usageError("there should be one argument for resetpassword command - user name")
+	}
+
+	admins, err := s.db.CountAdminsWithPassword(context.Background())
+	if err != nil {
+		slog.Error("error while counting admins", "error", err)
+
+		return err
+	}
+
+	if admins > 0 {
+		currentUser, err := getSessionUser(s)
+		if err != nil {
+			return err
+		}
+
+		if currentUser.Role != roleAdmin {
+			return notAllowedError("%s command is available only to admins", cmd.name)
+		}
+	}
+
+	user, err := s.db.GetUserByName(context.Background(), cmd.arguments[0])
+	if err != nil {
+		slog.Error("no such user", "name", cmd.arguments[0], "error", err)
+
+		return err
+	}
+
+	if admins == 0 && user.Role != roleAdmin {
+		return notAllowedError("only admins can get reset token before some admin sets a password")
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.db.SetPasswordReset(context.Background(), database.SetPasswordResetParams{
+		UserID: user.ID,
+		TokenHash: hashToken(token),
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(passwordResetDuration),
+	})
+	if err != nil {
+		slog.Error("error while creating reset token", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("reset token for %s, valid for %.0f hours - use it with login --reset %s\n", user.Name, passwordResetDuration.Hours(), user.Name)
+	fmt.Println(token)
+
+	return nil
+}
+
+// resetPassword sets a new password of the user after checking the reset token,
+// which is used up, and logs out sessions started with the old password
+func resetPassword(s *state, user database.User) error {
+	token, err := readPassword("Reset token: ")
+	if err != nil {
+		return err
+	}
+
+	password, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	used, err := s.db.UsePasswordReset(context.Background(), database.UsePasswordResetParams{
+		UserID: user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now(),
+	})
+	if err != nil {
+		slog.Error("error while checking reset token", "error", err)
+
+		return err
+	}
+
+	if used == 0 {
+		return notAllowedError("wrong or expired reset token")
+	}
+
+	err = s.db.DeleteUserSessions(context.Background(), user.ID)
+	if err != nil {
+		slog.Error("error while removing sessions", "error", err)
+
+		return err
+	}
+
+	err = s.db.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+		PasswordHash: passwordHash,
+		UpdatedAt: time.Now(),
+		ID: user.ID,
+	})
+	if err != nil {
+		slog.Error("error while setting password", "error", err)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,460 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"internal/config"
+	"internal/database"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+const (
+	digestDaily = "daily"
+	digestWeekly = "weekly"
+)
+
+const digestTextTemplate = `Hi {{.UserName}},
+
+here are {{.Count}} unread posts from your feeds since {{.Since.Format "2006-01-02 15:04"}}.{{if .Truncated}}
+Only the first {{.Shown}} of them are listed, the rest are waiting in gator.{{end}}
+{{range .Groups}}{{if .Folder}}
+== {{.Folder}} ==
+{{end}}{{range .Feeds}}
+{{.Name}}
+{{range .Posts}}  * {{or .Title.String .Url}}
+    {{.Url}}
+{{end}}{{end}}{{end}}
+--
+gator
+`
+
+const digestHTMLTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #222;">
+<p>Hi {{.UserName}},</p>
+<p>here are {{.Count}} unread posts from your feeds since {{.Since.Format "2006-01-02 15:04"}}.</p>
+{{if .Truncated}}<p>Only the first {{.Shown}} of them are listed, the rest are waiting in gator.</p>{{end}}
+{{range .Groups}}
+{{if .Folder}}<h2>{{.Folder}}</h2>{{end}}
+{{range .Feeds}}
+<h3>{{.Name}}</h3>
+<ul>
+{{range .Posts}}<li><a href="{{.Url}}">{{or .Title.String .Url}}</a><br><small>{{summary .Description.String}}</small></li>
+{{end}}
+</ul>
+{{end}}
+{{end}}
+<p style="color: #777;">gator</p>
+</body>
+</html>
+`
+
+var (
+	digestText = template.Must(template.New("digest").Parse(digestTextTemplate))
+	digestHTML = htmltemplate.Must(htmltemplate.New("digest").Funcs(htmltemplate.FuncMap{ "summary": summary }).Parse(digestHTMLTemplate))
+)
+
+type digestFeed struct {
+	Name string
+	Posts []database.GetDigestPostsForUserRow
+}
+
+type digestGroup struct {
+	Folder string
+	Feeds []digestFeed
+}
+
+type digest struct {
+	UserName string
+	Frequency string
+	Since time.Time
+	// Count is the number of all unread posts, while only Shown of them are listed
+	Count int
+	Shown int
+	Groups []digestGroup
+}
+
+func digestPeriod(frequency string) time.Duration {
+	if frequency == digestWeekly {
+		return 7 * 24 * time.Hour
+	}
+
+	return 24 * time.Hour
+}
+
+// buildDigest collects unread posts added since the last digest, or during the last period
+// if no digest was sent yet, grouped by folder and feed
+func buildDigest(s *state, user database.User, frequency string, lastSentAt sql.NullTime) (digest, error) {
+	result := digest{
+		UserName: user.Name,
+		Frequency: frequency,
+		Since: time.Now().Add(-digestPeriod(frequency)),
+	}
+
+	if lastSentAt.Valid {
+		result.Since = lastSentAt.Time
+	}
+
+	posts, err := s.db.GetDigestPostsForUser(context.Background(), database.GetDigestPostsForUserParams{
+		UserID: user.ID,
+		Since: result.Since,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Shown = len(posts)
+	result.Groups = groupDigestPosts(posts)
+
+	// every row carries the count of all matching posts, as the query lists only some of them
+	if len(posts) != 0 {
+		result.Count = int(posts[0].TotalCount)
+	}
+
+	return result, nil
+}
+
+// groupDigestPosts groups posts ordered by folder and feed, groups are built by watching for changes
+func groupDigestPosts(posts []database.GetDigestPostsForUserRow) []digestGroup {
+	var groups []digestGroup
+
+	for i, post := range posts {
+		if i == 0 || post.FolderName != posts[i - 1].FolderName {
+			groups = append(groups, digestGroup{ Folder: post.FolderName.String })
+		}
+
+		group := &groups[len(groups) - 1]
+
+		if len(group.Feeds) == 0 || group.Feeds[len(group.Feeds) - 1].Name != post.FeedName {
+			group.Feeds = append(group.Feeds, digestFeed{ Name: post.FeedName })
+		}
+
+		feed := &group.Feeds[len(group.Feeds) - 1]
+		feed.Posts = append(feed.Posts, post)
+	}
+
+	return groups
+}
+
+func (d digest) Truncated() bool {
+	return d.Shown < d.Count
+}
+
+func (d digest) subject() string {
+	return fmt.Sprintf("Your %s gator digest: %d unread posts", d.Frequency, d.Count)
+}
+
+func (d digest) render() (string, string, error) {
+	var text, html bytes.Buffer
+
+	err := digestText.Execute(&text, d)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = digestHTML.Execute(&html, d)
+	if err != nil {
+		return "", "", err
+	}
+
+	return text.String(), html.String(), nil
+}
+
+// digestMessage builds multipart email with both plain text and html version of the digest
+func digestMessage(from string, to string, d digest) ([]byte, error) {
+	text, html, err := d.render()
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content string
+	}{
+		{ "text/plain; charset=utf-8", text },
+		{ "text/html; charset=utf-8", html },
+	}
+
+	for _, part := range parts {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type": { part.contentType },
+			"Content-Transfer-Encoding": { "quoted-printable" },
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := quotedprintable.NewWriter(partWriter)
+
+		_, err = encoder.Write([]byte(part.content))
+		if err != nil {
+			return nil, err
+		}
+
+		err = encoder.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", d.subject()))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+func sendDigest(smtpConfig *config.SMTPConfig, to string, d digest) error {
+	if smtpConfig == nil || smtpConfig.Host == "" {
+		return usageError("smtp server is not configured, add smtp section to the config")
+	}
+
+	message, err := digestMessage(smtpConfig.From, to, d)
+	if err != nil {
+		return err
+	}
+
+	port := smtpConfig.Port
+	if port == 0 {
+		port = 25
+	}
+
+	var auth smtp.Auth
+	if smtpConfig.Username != "" {
+		auth = smtp.PlainAuth("", smtpConfig.Username, smtpConfig.Password, smtpConfig.Host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(smtpConfig.Host, strconv.Itoa(port)), auth, smtpConfig.From, []string{ to }, message)
+}
+
+// sendDueDigests is run by agg, digests failing to send are retried on the next run
+// and on shutdown the remaining ones are left for the next start
+func sendDueDigests(ctx context.Context, s *state) error {
+	if s.cfg.SMTP == nil {
+		return nil
+	}
+
+	subscriptions, err := s.db.GetDueDigestSubscriptions(ctx, time.Now())
+	if err != nil {
+		slog.Error("error while retrieving digest subscriptions", "error", err)
+
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		user := database.User{ ID: subscription.UserID, Name: subscription.UserName }
+		sentAt := time.Now()
+
+		userDigest, err := buildDigest(s, user, subscription.Frequency, subscription.LastSentAt)
+		if err != nil {
+			slog.Error("error while building digest", "error", err)
+
+			return err
+		}
+
+		// nothing to tell about, the next digest will cover the next period
+		if userDigest.Count != 0 {
+			err = sendDigest(s.cfg.SMTP, subscription.Email, userDigest)
+			if err != nil {
+				slog.Error("error while sending digest", "user_id", subscription.UserID, "email", subscription.Email, "error", err)
+
+				continue
+			}
+
+			slog.Info("sent digest", "user_id", subscription.UserID, "email", subscription.Email, "posts", userDigest.Count)
+		}
+
+		err = s.db.MarkDigestSent(context.Background(), database.MarkDigestSentParams{
+			LastSentAt: sql.NullTime{ Time: sentAt, Valid: true },
+			UserID: subscription.UserID,
+		})
+		if err != nil {
+			slog.Error("error while marking digest as sent", "error", err)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func handlerDigest(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) < 1 {
+		return usageError("digest command expects a subcommand - subscribe <email> [daily|weekly], unsubscribe, preview or send")
+	}
+
+	switch cmd.arguments[0] {
+	case "subscribe":
+		if len(cmd.arguments) != 2 && len(cmd.arguments) != 3 {
+			return usageError("digest subscribe command expects email and optional frequency - daily or weekly")
+		}
+
+		address, err := mail.ParseAddress(cmd.arguments[1])
+		if err != nil {
+			slog.Error("error while parsing email address", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		frequency := digestDaily
+		if len(cmd.arguments) == 3 {
+			frequency = cmd.arguments[2]
+		}
+
+		if frequency != digestDaily && frequency != digestWeekly {
+			return usageError("digest frequency should be either daily or weekly")
+		}
+
+		subscription, err := s.db.SetDigestSubscription(context.Background(), database.SetDigestSubscriptionParams{
+			UserID: currentUser.ID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Email: address.Address,
+			Frequency: frequency,
+		})
+		if err != nil {
+			slog.Error("error while saving digest subscription", "error", err)
+
+			return err
+		}
+
+		fmt.Printf("successfully subscribed %s to %s digest\n", subscription.Email, subscription.Frequency)
+	case "unsubscribe":
+		removed, err := s.db.RemoveDigestSubscription(context.Background(), currentUser.ID)
+		if err != nil {
+			slog.Error("error while removing digest subscription", "error", err)
+
+			return err
+		}
+
+		if removed == 0 {
+			return notFoundError("you are not subscribed to digest")
+		}
+
+		fmt.Println("successfull digest unsubscribe")
+	case "preview":
+		flags := flag.NewFlagSet("digest preview", flag.ContinueOnError)
+		html := flags.Bool("html", false, "print html version of the digest instead of plain text")
+
+		err := parseFlags(flags, cmd.arguments[1:])
+		if err != nil {
+			return err
+		}
+
+		if flags.NArg() != 0 {
+			return usageError("digest preview command accepts only --html flag")
+		}
+
+		// users without subscription see what a daily digest would look like
+		subscription, err := s.db.GetDigestSubscription(context.Background(), currentUser.ID)
+		if err != nil && err != sql.ErrNoRows {
+			slog.Error("error while retrieving digest subscription", "error", err)
+
+			return err
+		}
+
+		if err == sql.ErrNoRows {
+			subscription.Frequency = digestDaily
+		}
+
+		userDigest, err := buildDigest(s, currentUser, subscription.Frequency, subscription.LastSentAt)
+		if err != nil {
+			slog.Error("error while building digest", "error", err)
+
+			return err
+		}
+
+		text, htmlText, err := userDigest.render()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Subject: %s\n\n", userDigest.subject())
+
+		if *html {
+			fmt.Print(htmlText)
+		} else {
+			fmt.Print(text)
+		}
+	case "send":
+		subscription, err := s.db.GetDigestSubscription(context.Background(), currentUser.ID)
+		if err == sql.ErrNoRows {
+			return notFoundError("you are not subscribed to digest, use digest subscribe first")
+		}
+		if err != nil {
+			slog.Error("error while retrieving digest subscription", "error", err)
+
+			return err
+		}
+
+		sentAt := time.Now()
+
+		userDigest, err := buildDigest(s, currentUser, subscription.Frequency, subscription.LastSentAt)
+		if err != nil {
+			slog.Error("error while building digest", "error", err)
+
+			return err
+		}
+
+		if userDigest.Count == 0 {
+			fmt.Printf("no new posts since %s, digest isn't sent\n", userDigest.Since.Format(time.DateTime))
+
+			return nil
+		}
+
+		err = sendDigest(s.cfg.SMTP, subscription.Email, userDigest)
+		if err != nil {
+			slog.Error("error while sending digest", "error", err)
+
+			return err
+		}
+
+		err = s.db.MarkDigestSent(context.Background(), database.MarkDigestSentParams{
+			LastSentAt: sql.NullTime{ Time: sentAt, Valid: true },
+			UserID: currentUser.ID,
+		})
+		if err != nil {
+			slog.Error("error while marking digest as sent", "error", err)
+
+			return err
+		}
+
+		fmt.Printf("successfully sent digest with %d posts to %s\n", userDigest.Count, subscription.Email)
+	default:
+		return usageError("no such digest subcommand - %s", cmd.arguments[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"internal/config"
+	"internal/database"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type smtpMessage struct {
+	from string
+	to []string
+	data string
+}
+
+// fakeSMTPServer accepts a single connection and speaks just enough smtp
+// for smtp.SendMail without tls and auth
+func fakeSMTPServer(t *testing.T) (*config.SMTPConfig, <-chan smtpMessage) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error while listening: %v", err)
+	}
+
+	t.Cleanup(func() {
+		listener.Close()
+	})
+
+	messages := make(chan smtpMessage, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		text.PrintfLine("220 localhost ESMTP")
+
+		var message smtpMessage
+
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+
+			verb, argument, _ := strings.Cut(line, " ")
+
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				text.PrintfLine("250 localhost")
+			case "MAIL":
+				message.from = argument
+				text.PrintfLine("250 OK")
+			case "RCPT":
+				message.to = append(message.to, argument)
+				text.PrintfLine("250 OK")
+			case "DATA":
+				text.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+
+				message.data = string(data)
+				text.PrintfLine("250 OK")
+			case "QUIT":
+				text.PrintfLine("221 bye")
+				messages <- message
+
+				return
+			default:
+				text.PrintfLine("502 command not implemented")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	return &config.SMTPConfig{ Host: host, Port: portNumber, From: "gator@example.com" }, messages
+}
+
+func testDigestPosts() []database.GetDigestPostsForUserRow {
+	published := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	return []database.GetDigestPostsForUserRow{
+		{ FeedName: "Alpha", Title: sql.NullString{ String: "First", Valid: true }, Url: "https://alpha.example.com/1", PublishedAt: published, TotalCount: 250 },
+		{ FeedName: "Alpha", Title: sql.NullString{ String: "Second", Valid: true }, Url: "https://alpha.example.com/2", PublishedAt: published, TotalCount: 250 },
+		{ FolderName: sql.NullString{ String: "news", Valid: true }, FeedName: "Beta", Url: "https://beta.example.com/1", PublishedAt: published, TotalCount: 250 },
+		{ FolderName: sql.NullString{ String: "news", Valid: true }, FeedName: "Gamma", Url: "https://gamma.example.com/1", PublishedAt: published, TotalCount: 250 },
+	}
+}
+
+func TestGroupDigestPosts(t *testing.T) {
+	groups := groupDigestPosts(testDigestPosts())
+
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+
+	if groups[0].Folder != "" || len(groups[0].Feeds) != 1 || len(groups[0].Feeds[0].Posts) != 2 {
+		t.Errorf("first group = %+v, want two posts of Alpha outside of folders", groups[0])
+	}
+
+	if groups[1].Folder != "news" || len(groups[1].Feeds) != 2 || groups[1].Feeds[0].Name != "Beta" || groups[1].Feeds[1].Name != "Gamma" {
+		t.Errorf("second group = %+v, want Beta and Gamma in news", groups[1])
+	}
+
+	if groupDigestPosts(nil) != nil {
+		t.Errorf("groupDigestPosts(nil) isn't empty")
+	}
+}
+
+func TestDigestRenderTruncated(t *testing.T) {
+	posts := testDigestPosts()
+
+	tests := []struct {
+		name string
+		count int
+		wantNote bool
+	}{
+		{ name: "all posts listed", count: len(posts), wantNote: false },
+		{ name: "more posts than listed", count: 250, wantNote: true },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := digest{ UserName: "alice", Frequency: digestDaily, Count: test.count, Shown: len(posts), Groups: groupDigestPosts(posts) }
+
+			text, html, err := d.render()
+			if err != nil {
+				t.Fatalf("render() error = %v", err)
+			}
+
+			if want := strconv.Itoa(test.count) + " unread posts"; !strings.Contains(d.subject(), want) || !strings.Contains(text, want) || !strings.Contains(html, want) {
+				t.Errorf("digest doesn't mention %q", want)
+			}
+
+			note := "Only the first 4 of them are listed"
+			if strings.Contains(text, note) != test.wantNote || strings.Contains(html, note) != test.wantNote {
+				t.Errorf("truncation note shown in text %t and html %t, want %t", strings.Contains(text, note), strings.Contains(html, note), test.wantNote)
+			}
+		})
+	}
+}
+
+func TestSendDigest(t *testing.T) {
+	smtpConfig, messages := fakeSMTPServer(t)
+
+	posts := testDigestPosts()
+	d := digest{ UserName: "alice", Frequency: digestWeekly, Count: 250, Shown: len(posts), Groups: groupDigestPosts(posts) }
+
+	err := sendDigest(smtpConfig, "alice@example.com", d)
+	if err != nil {
+		t.Fatalf("sendDigest() error = %v", err)
+	}
+
+	var message smtpMessage
+
+	select {
+	case message = <-messages:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("smtp server didn't receive the digest")
+	}
+
+	if message.from != "FROM:<gator@example.com>" || len(message.to) != 1 || message.to[0] != "TO:<alice@example.com>" {
+		t.Errorf("envelope %s %q, want from gator@example.com to alice@example.com", message.from, message.to)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(message.data))
+	if err != nil {
+		t.Fatalf("message isn't valid mail: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "Your weekly gator digest: 250 unread posts" {
+		t.Errorf("subject = %q, want weekly digest of 250 unread posts", subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type = %q, want multipart/alternative", parsed.Header.Get("Content-Type"))
+	}
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	bodies := map[string]string{}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("error while reading message part: %v", err)
+		}
+
+		// quoted-printable parts are decoded by multipart reader
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("error while reading message part: %v", err)
+		}
+
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[contentType] = string(body)
+	}
+
+	if !strings.Contains(bodies["text/plain"], "== news ==") || !strings.Contains(bodies["text/plain"], "https://gamma.example.com/1") {
+		t.Errorf("text part = %q, want posts grouped by folder", bodies["text/plain"])
+	}
+
+	if !strings.Contains(bodies["text/html"], `<a href="https://alpha.example.com/1">First</a>`) {
+		t.Errorf("html part = %q, want links to posts", bodies["text/html"])
+	}
+}
+
+func TestSendDigestNotConfigured(t *testing.T) {
+	err := sendDigest(nil, "alice@example.com", digest{})
+	if !errors.Is(err, errUsage) {
+		t.Errorf("sendDigest() without smtp config error = %v, want usage error", err)
+	}
+}
+
+func TestDigestSendNothingNew(t *testing.T) {
+	user := database.User{ ID: uuid.New(), Name: "alice" }
+
+	s, db := newFakeState(t, map[string]fakeResult{
+		"GetDigestSubscription": { rows: [][]driver.Value{ { user.ID.String(), time.Now(), time.Now(), "alice@example.com", "daily", nil } } },
+		"GetDigestPostsForUser": {},
+	})
+
+	// smtp isn't configured, so sending would fail
+	s.cfg = &config.Config{}
+
+	err := handlerDigest(s, command{ name: "digest", arguments: []string{ "send" } }, user)
+	if err != nil {
+		t.Fatalf("handlerDigest() error = %v, want empty digest skipped", err)
+	}
+
+	if calls := len(db.called("MarkDigestSent")); calls != 0 {
+		t.Errorf("MarkDigestSent called %d times, want empty digest left unsent", calls)
+	}
+}
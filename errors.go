@@ -0,0 +1,91 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+
+	"github.com/lib/pq"
+)
+
+const (
+	exitOK = 0
+	exitInternal = 1
+	exitUsage = 2
+	exitNotFound = 3
+	exitConflict = 4
+	exitNotAllowed = 5
+)
+
+var (
+	errUsage = errors.New("wrong usage")
+	errNotFound = errors.New("not found")
+	errConflict = errors.New("conflict")
+	errNotAllowed = errors.New("not allowed")
+)
+
+// uniqueViolation is the postgres error code for duplicate key values
+const uniqueViolation = "23505"
+
+func usageError(format string, a ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, a...))
+}
+
+func notFoundError(format string, a ...any) error {
+	return fmt.Errorf("%w: %s", errNotFound, fmt.Sprintf(format, a...))
+}
+
+func notAllowedError(format string, a ...any) error {
+	return fmt.Errorf("%w: %s", errNotAllowed, fmt.Sprintf(format, a...))
+}
+
+func parseFlags(flags *flag.FlagSet, arguments []string) error {
+	err := flags.Parse(arguments)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+
+	return nil
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var pqErr *pq.Error
+
+	switch {
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, errNotFound), errors.Is(err, sql.ErrNoRows):
+		return exitNotFound
+	case errors.Is(err, errConflict):
+		return exitConflict
+	case errors.As(err, &pqErr) && pqErr.Code == uniqueViolation:
+		return exitConflict
+	case errors.Is(err, errNotAllowed):
+		return exitNotAllowed
+	}
+
+	return exitInternal
+}
+
+// httpStatus maps errors to response statuses of the api in the same way
+// exitCode maps them to exit codes of commands
+func httpStatus(err error) int {
+	switch exitCode(err) {
+	case exitUsage:
+		return http.StatusBadRequest
+	case exitNotFound:
+		return http.StatusNotFound
+	case exitConflict:
+		return http.StatusConflict
+	case exitNotAllowed:
+		return http.StatusForbidden
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err error
+		want int
+	}{
+		{ name: "success", err: nil, want: exitOK },
+		{ name: "usage", err: usageError("login expects a name"), want: exitUsage },
+		{ name: "wrapped usage", err: fmt.Errorf("%w: %w", errUsage, errors.New("bad duration")), want: exitUsage },
+		{ name: "not found", err: notFoundError("no such feed"), want: exitNotFound },
+		{ name: "no rows", err: sql.ErrNoRows, want: exitNotFound },
+		{ name: "wrapped no rows", err: fmt.Errorf("looking up user: %w", sql.ErrNoRows), want: exitNotFound },
+		{ name: "conflict", err: errConflict, want: exitConflict },
+		{ name: "unique violation", err: &pq.Error{ Code: uniqueViolation }, want: exitConflict },
+		{ name: "other postgres error", err: &pq.Error{ Code: "23503" }, want: exitInternal },
+		{ name: "not allowed", err: notAllowedError("admins only"), want: exitNotAllowed },
+		{ name: "internal", err: errors.New("connection refused"), want: exitInternal },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := exitCode(test.err)
+			if got != test.want {
+				t.Errorf("exitCode(%v) = %d, want %d", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err error
+		want int
+	}{
+		{ name: "usage", err: usageError("feed_id should be a uuid"), want: http.StatusBadRequest },
+		{ name: "not found", err: notFoundError("no such post"), want: http.StatusNotFound },
+		{ name: "no rows", err: sql.ErrNoRows, want: http.StatusNotFound },
+		{ name: "conflict", err: errConflict, want: http.StatusConflict },
+		{ name: "unique violation", err: &pq.Error{ Code: uniqueViolation }, want: http.StatusConflict },
+		{ name: "not allowed", err: notAllowedError("admins only"), want: http.StatusForbidden },
+		{ name: "internal", err: errors.New("connection refused"), want: http.StatusInternalServerError },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := httpStatus(test.err)
+			if got != test.want {
+				t.Errorf("httpStatus(%v) = %d, want %d", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	limit := flags.Int("limit", 10, "")
+
+	err := parseFlags(flags, []string{ "--limit", "5", "rest" })
+	if err != nil || *limit != 5 || flags.Arg(0) != "rest" {
+		t.Errorf("parseFlags() = %v with limit %d and arguments %q", err, *limit, flags.Args())
+	}
+
+	err = parseFlags(flags, []string{ "--unknown" })
+	if exitCode(err) != exitUsage {
+		t.Errorf("parseFlags() with unknown flag error = %v, want usage error", err)
+	}
+}
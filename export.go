@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"internal/database"
+	"internal/opml"
+	"io"
+	"log/slog"
+	"os"
+)
+
+func handlerExport(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("output", "", "file to write OPML to instead of standard output")
+	folder := flags.String("folder", "", "export only feeds of this folder")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return usageError("export command accepts only --output and --folder flags")
+	}
+
+	if *folder != "" {
+		_, err = s.db.GetFolderByName(context.Background(), database.GetFolderByNameParams{
+			UserID: currentUser.ID,
+			Name: *folder,
+		})
+		if err == sql.ErrNoRows {
+			return notFoundError("no such folder %s", *folder)
+		}
+		if err != nil {
+			slog.Error("error while retrieving folder to export", "error", err)
+
+			return err
+		}
+	}
+
+	user_feed_follows, err := s.db.GetFeedFollowsForUser(context.Background(), currentUser.ID)
+	if err != nil {
+		slog.Error("error while retrieving feeds followed by current user", "error", err)
+
+		return err
+	}
+
+	doc := opml.New(fmt.Sprintf("gator subscriptions of %s", currentUser.Name))
+
+	// follows are ordered by folder, so feeds of one folder are next to each other
+	for _, follows := range user_feed_follows {
+		if *folder != "" && follows.FolderName.String != *folder {
+			continue
+		}
+
+		outline := opml.FeedOutline(follows.DisplayName, follows.FeedUrl)
+
+		if !follows.FolderName.Valid {
+			doc.Body.Outline = append(doc.Body.Outline, outline)
+
+			continue
+		}
+
+		last := len(doc.Body.Outline) - 1
+		if last < 0 || doc.Body.Outline[last].Text != follows.FolderName.String || doc.Body.Outline[last].XMLURL != "" {
+			doc.Body.Outline = append(doc.Body.Outline, opml.FolderOutline(follows.FolderName.String, nil))
+			last++
+		}
+
+		doc.Body.Outline[last].Outline = append(doc.Body.Outline[last].Outline, outline)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			slog.Error("error while creating output file", "error", err)
+
+			return err
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	return doc.Write(w)
+}
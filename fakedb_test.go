@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"internal/database"
+	"io"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+// fakeDB answers queries by their sqlc name, so handlers can be tested without postgres
+type fakeDB struct {
+	mu sync.Mutex
+	results map[string]fakeResult
+	calls []fakeCall
+}
+
+// fakeResult holds rows of :one and :many queries in the order of their columns,
+// or rows affected by :exec and :execrows queries
+type fakeResult struct {
+	rows [][]driver.Value
+	rowsAffected int64
+	err error
+}
+
+type fakeCall struct {
+	name string
+	args []driver.Value
+}
+
+var queryName = regexp.MustCompile(`^-- name: (\w+)`)
+
+// newFakeState returns state backed by fakeDB, queries missing from results fail the test
+func newFakeState(t *testing.T, results map[string]fakeResult) (*state, *fakeDB) {
+	db := &fakeDB{ results: results }
+
+	sqlDB := sql.OpenDB(db)
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+
+	return &state{ db: database.New(sqlDB), sqlDB: sqlDB }, db
+}
+
+// called returns arguments of each call of the query
+func (db *fakeDB) called(name string) [][]driver.Value {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var args [][]driver.Value
+	for _, call := range db.calls {
+		if call.name == name {
+			args = append(args, call.args)
+		}
+	}
+
+	return args
+}
+
+func (db *fakeDB) record(name string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.calls = append(db.calls, fakeCall{ name: name })
+}
+
+// order returns names of the calls in the order they were made
+func (db *fakeDB) order() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	names := []string{}
+	for _, call := range db.calls {
+		names = append(names, call.name)
+	}
+
+	return names
+}
+
+func (db *fakeDB) query(query string, args []driver.NamedValue) (fakeResult, error) {
+	match := queryName.FindStringSubmatch(query)
+	if match == nil {
+		return fakeResult{}, fmt.Errorf("query without sqlc name: %s", query)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	call := fakeCall{ name: match[1] }
+	for _, arg := range args {
+		call.args = append(call.args, arg.Value)
+	}
+
+	db.calls = append(db.calls, call)
+
+	result, found := db.results[call.name]
+	if !found {
+		return fakeResult{}, fmt.Errorf("unexpected query %s", call.name)
+	}
+
+	return result, result.err
+}
+
+func (db *fakeDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return fakeConn{ db: db }, nil
+}
+
+func (db *fakeDB) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fake database doesn't prepare statements")
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+// Begin starts a transaction which only records its outcome, queries take effect right away
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{ db: c.db }, nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx fakeTx) Commit() error {
+	tx.db.record("COMMIT")
+
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.record("ROLLBACK")
+
+	return nil
+}
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result, err := c.db.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.RowsAffected(result.rowsAffected), nil
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result, err := c.db.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fakeRows{ rows: result.rows }, nil
+}
+
+// CheckNamedValue passes arguments through as the fake database doesn't care for their types,
+// only values of driver.Valuer are resolved to be comparable in tests
+func (c fakeConn) CheckNamedValue(value *driver.NamedValue) error {
+	valuer, ok := value.Value.(driver.Valuer)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	value.Value, err = valuer.Value()
+
+	return err
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+
+	columns := make([]string, len(r.rows[0]))
+	for i := range columns {
+		columns[i] = fmt.Sprintf("column%d", i)
+	}
+
+	return columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(r.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[r.next])
+	r.next++
+
+	return nil
+}
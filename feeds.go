@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"time"
+)
+
+func handlerRemoveFeed(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("rmfeed", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageError("there should be one argument for rmfeed command - url of feed to remove")
+	}
+
+	feed, err := getOwnedFeed(s, flags.Arg(0), currentUser)
+	if err != nil {
+		return err
+	}
+
+	stats, err := s.db.GetFeedStats(context.Background(), feed.ID)
+	if err != nil {
+		slog.Error("error while counting follows and posts of the feed", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("removing feed \"%s\" will also remove %d follows and %d posts with %d stars, %d notes and %d tags of their readers\n", feed.Name, stats.FollowsCount, stats.PostsCount, stats.StarredCount, stats.NotedCount, stats.TagsCount)
+
+	if !*yes {
+		confirmed, err := confirm("remove the feed?")
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Println("feed is not removed")
+
+			return nil
+		}
+	}
+
+	_, err = s.db.DeleteFeed(context.Background(), feed.ID)
+	if err != nil {
+		slog.Error("error while removing feed", "error", err)
+
+		return err
+	}
+
+	fmt.Println("successfull feed remove")
+
+	return nil
+}
+
+func handlerRenameFeed(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 2 {
+		return usageError("there should be two arguments for renamefeed command - feed url and new feed name")
+	}
+
+	feed, err := getOwnedFeed(s, cmd.arguments[0], currentUser)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.RenameFeed(context.Background(), database.RenameFeedParams{
+		Name: cmd.arguments[1],
+		UpdatedAt: time.Now(),
+		ID: feed.ID,
+	})
+	if err != nil {
+		slog.Error("error while renaming feed", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("successfully renamed feed \"%s\" to \"%s\"\n", feed.Name, cmd.arguments[1])
+
+	return nil
+}
+
+func handlerSetFeedURL(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("setfeedurl", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 2 {
+		return usageError("there should be two arguments for setfeedurl command - current feed url and new feed url")
+	}
+
+	feed, err := getOwnedFeed(s, flags.Arg(0), currentUser)
+	if err != nil {
+		return err
+	}
+
+	stats, err := s.db.GetFeedStats(context.Background(), feed.ID)
+	if err != nil {
+		slog.Error("error while counting follows and posts of the feed", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("%d follows and %d posts of feed \"%s\" will be kept under the new url\n", stats.FollowsCount, stats.PostsCount, feed.Name)
+
+	if !*yes {
+		confirmed, err := confirm(fmt.Sprintf("change url to %s?", flags.Arg(1)))
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Println("feed url is not changed")
+
+			return nil
+		}
+	}
+
+	err = s.db.SetFeedURL(context.Background(), database.SetFeedURLParams{
+		Url: flags.Arg(1),
+		UpdatedAt: time.Now(),
+		ID: feed.ID,
+	})
+	if err != nil {
+		slog.Error("error while changing feed url", "error", err)
+
+		return err
+	}
+
+	fmt.Println("successfull feed url change")
+
+	return nil
+}
+
+func handlerTransferFeed(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("transferfeed", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 2 {
+		return usageError("there should be two arguments for transferfeed command - feed url and name of the new owner")
+	}
+
+	feed, err := getOwnedFeed(s, flags.Arg(0), currentUser)
+	if err != nil {
+		return err
+	}
+
+	newOwner, err := s.db.GetUserByName(context.Background(), flags.Arg(1))
+	if err != nil {
+		slog.Error("no such user", "name", flags.Arg(1), "error", err)
+
+		return err
+	}
+
+	if !*yes {
+		confirmed, err := confirm(fmt.Sprintf("transfer feed \"%s\" to %s?", feed.Name, newOwner.Name))
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Println("feed is not transferred")
+
+			return nil
+		}
+	}
+
+	err = s.db.TransferFeed(context.Background(), database.TransferFeedParams{
+		UserID: newOwner.ID,
+		UpdatedAt: time.Now(),
+		ID: feed.ID,
+	})
+	if err != nil {
+		slog.Error("error while transferring feed", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("successfully transferred feed \"%s\" to %s\n", feed.Name, newOwner.Name)
+
+	return nil
+}
+
+// getOwnedFeed returns the feed if current user may change it - added it or is an admin
+func getOwnedFeed(s *state, feedURL string, currentUser database.User) (database.Feed, error) {
+	feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+	if err != nil {
+		slog.Error("error while retrieving feed", "error", err)
+
+		return database.Feed{}, err
+	}
+
+	if feed.UserID != currentUser.ID && currentUser.Role != roleAdmin {
+		return database.Feed{}, notAllowedError("only the user who added feed \"%s\" can change it", feed.Name)
+	}
+
+	return feed, nil
+}
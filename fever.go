@@ -0,0 +1,481 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fever API is spoken by mobile readers like Reeder and NetNewsWire,
+// see https://feedafever.com/api for the protocol description
+
+type feverGroup struct {
+	ID int64 `json:"id"`
+	Title string `json:"title"`
+}
+
+type feverFeedsGroup struct {
+	GroupID int64 `json:"group_id"`
+	FeedIDs string `json:"feed_ids"`
+}
+
+type feverFeed struct {
+	ID int64 `json:"id"`
+	FaviconID int64 `json:"favicon_id"`
+	Title string `json:"title"`
+	URL string `json:"url"`
+	SiteURL string `json:"site_url"`
+	IsSpark int `json:"is_spark"`
+	LastUpdatedOnTime int64 `json:"last_updated_on_time"`
+}
+
+type feverItem struct {
+	ID int64 `json:"id"`
+	FeedID int64 `json:"feed_id"`
+	Title string `json:"title"`
+	Author string `json:"author"`
+	HTML string `json:"html"`
+	URL string `json:"url"`
+	IsSaved int `json:"is_saved"`
+	IsRead int `json:"is_read"`
+	CreatedOnTime int64 `json:"created_on_time"`
+}
+
+// feverAPIKey computes the key fever clients send, md5 of "username:password"
+func feverAPIKey(name string, password string) string {
+	hash := md5.Sum([]byte(name + ":" + password))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func handlerFever(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 1 {
+		return usageError("fever command expects a subcommand - enable or disable")
+	}
+
+	switch cmd.arguments[0] {
+	case "enable":
+		// fever protocol needs a key derived from plain password, so a separate
+		// password is used instead of the account one
+		fmt.Println("choose a password used only by fever clients")
+
+		password, err := readNewPassword()
+		if err != nil {
+			return err
+		}
+
+		err = s.db.SetFeverAPIKey(context.Background(), database.SetFeverAPIKeyParams{
+			UserID: currentUser.ID,
+			ApiKey: feverAPIKey(currentUser.Name, password),
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			slog.Error("error while saving fever credentials", "error", err)
+
+			return err
+		}
+
+		fmt.Printf("successfully enabled fever api, login as %s with the chosen password\n", currentUser.Name)
+		fmt.Println("enable it again after renaming the user")
+	case "disable":
+		removed, err := s.db.RemoveFeverAPIKey(context.Background(), currentUser.ID)
+		if err != nil {
+			slog.Error("error while removing fever credentials", "error", err)
+
+			return err
+		}
+
+		if removed == 0 {
+			return notFoundError("fever api is not enabled")
+		}
+
+		fmt.Println("successfull fever api disable")
+	default:
+		return usageError("no such fever subcommand - %s", cmd.arguments[0])
+	}
+
+	return nil
+}
+
+func handlerFeverAPI(s *state) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		if err != nil {
+			respondWithError(w, r, fmt.Errorf("%w: %w", errUsage, err))
+
+			return
+		}
+
+		if !r.Form.Has("api") {
+			respondWithError(w, r, usageError("fever endpoint expects api parameter"))
+
+			return
+		}
+
+		response := map[string]any{ "api_version": 3, "auth": 0 }
+
+		user, err := s.db.GetUserByFeverAPIKey(r.Context(), strings.ToLower(r.Form.Get("api_key")))
+		if err == sql.ErrNoRows {
+			respondWithJSON(w, http.StatusOK, response)
+
+			return
+		}
+		if err != nil {
+			respondWithError(w, r, err)
+
+			return
+		}
+
+		response["auth"] = 1
+
+		err = feverRespond(s, r, user, response)
+		if err != nil {
+			respondWithError(w, r, err)
+
+			return
+		}
+
+		respondWithJSON(w, http.StatusOK, response)
+	}
+}
+
+func feverRespond(s *state, r *http.Request, user database.User, response map[string]any) error {
+	if r.Form.Get("mark") != "" {
+		err := feverMark(s, r, user)
+
+		// only the mark parameters are recorded, form holds api key as well
+		marked := url.Values{}
+		for _, name := range []string{ "mark", "as", "id", "before" } {
+			if r.Form.Has(name) {
+				marked.Set(name, r.Form.Get(name))
+			}
+		}
+
+		recordAudit(s, &user, r.Method + " " + r.URL.Path, marked.Encode(), err)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	feeds, err := s.db.GetFeverFeedsForUser(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	var lastRefreshed int64
+	for _, feed := range feeds {
+		if feed.LastFetchedAt.Valid && feed.LastFetchedAt.Time.Unix() > lastRefreshed {
+			lastRefreshed = feed.LastFetchedAt.Time.Unix()
+		}
+	}
+
+	response["last_refreshed_on_time"] = lastRefreshed
+
+	if r.Form.Has("groups") {
+		folders, err := s.db.GetFoldersForUser(r.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		groups := []feverGroup{}
+		for _, folder := range folders {
+			groups = append(groups, feverGroup{ ID: folder.ShortID, Title: folder.Name })
+		}
+
+		response["groups"] = groups
+		response["feeds_groups"] = feverFeedsGroups(feeds)
+	}
+
+	if r.Form.Has("feeds") {
+		result := []feverFeed{}
+		for _, feed := range feeds {
+			item := feverFeed{
+				ID: feed.ShortID,
+				Title: feed.Title,
+				URL: feed.Url,
+				SiteURL: feed.Url,
+			}
+
+			if feed.LastFetchedAt.Valid {
+				item.LastUpdatedOnTime = feed.LastFetchedAt.Time.Unix()
+			}
+
+			result = append(result, item)
+		}
+
+		response["feeds"] = result
+		response["feeds_groups"] = feverFeedsGroups(feeds)
+	}
+
+	if r.Form.Has("favicons") {
+		response["favicons"] = []any{}
+	}
+
+	if r.Form.Has("links") {
+		response["links"] = []any{}
+	}
+
+	if r.Form.Has("items") {
+		params, err := feverItemsParams(r, user)
+		if err != nil {
+			return err
+		}
+
+		items, err := s.db.GetFeverItemsForUser(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		result := []feverItem{}
+		for _, item := range items {
+			result = append(result, feverItem{
+				ID: item.ShortID,
+				FeedID: item.FeedShortID,
+				Title: item.Title.String,
+				Author: item.Author.String,
+				HTML: item.Description.String,
+				URL: item.Url,
+				IsSaved: feverBool(item.IsSaved),
+				IsRead: feverBool(item.IsRead),
+				CreatedOnTime: item.PublishedAt.Unix(),
+			})
+		}
+
+		total, err := s.db.CountFeverItemsForUser(r.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		response["items"] = result
+		response["total_items"] = total
+	}
+
+	if r.Form.Has("unread_item_ids") {
+		ids, err := s.db.GetFeverUnreadItemIDs(r.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		response["unread_item_ids"] = joinIDs(ids)
+	}
+
+	if r.Form.Has("saved_item_ids") {
+		ids, err := s.db.GetFeverSavedItemIDs(r.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		response["saved_item_ids"] = joinIDs(ids)
+	}
+
+	return nil
+}
+
+func feverItemsParams(r *http.Request, user database.User) (database.GetFeverItemsForUserParams, error) {
+	params := database.GetFeverItemsForUserParams{ UserID: user.ID }
+
+	if sinceID := r.Form.Get("since_id"); sinceID != "" {
+		id, err := strconv.ParseInt(sinceID, 10, 64)
+		if err != nil {
+			return params, usageError("since_id should be a number")
+		}
+
+		params.SinceID = sql.NullInt64{ Int64: id, Valid: true }
+	}
+
+	if maxID := r.Form.Get("max_id"); maxID != "" {
+		id, err := strconv.ParseInt(maxID, 10, 64)
+		if err != nil {
+			return params, usageError("max_id should be a number")
+		}
+
+		params.MaxID = sql.NullInt64{ Int64: id, Valid: true }
+	}
+
+	if withIDs := r.Form.Get("with_ids"); withIDs != "" {
+		ids := []int64{}
+		for _, value := range strings.Split(withIDs, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return params, usageError("with_ids should be comma separated numbers")
+			}
+
+			ids = append(ids, id)
+		}
+
+		params.WithIds = sql.NullString{ String: joinIDs(ids), Valid: true }
+	}
+
+	return params, nil
+}
+
+func feverMark(s *state, r *http.Request, user database.User) error {
+	id, err := strconv.ParseInt(r.Form.Get("id"), 10, 64)
+	if err != nil {
+		return usageError("id should be a number")
+	}
+
+	as := r.Form.Get("as")
+
+	switch r.Form.Get("mark") {
+	case "item":
+		// only posts of followed feeds can be marked
+		post, err := s.db.GetPostByShortIDForUser(r.Context(), database.GetPostByShortIDForUserParams{
+			ShortID: id,
+			UserID: user.ID,
+		})
+		if err == sql.ErrNoRows {
+			return notFoundError("no such item")
+		}
+		if err != nil {
+			return err
+		}
+
+		switch as {
+		case "read":
+			return s.db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+				UserID: user.ID,
+				PostID: post.ID,
+				ReadAt: time.Now(),
+			})
+		case "unread":
+			return s.db.MarkPostUnread(r.Context(), database.MarkPostUnreadParams{
+				UserID: user.ID,
+				PostID: post.ID,
+			})
+		case "saved":
+			_, err := s.db.SavePost(r.Context(), database.SavePostParams{
+				UserID: user.ID,
+				PostID: post.ID,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			})
+
+			return err
+		case "unsaved":
+			_, err := s.db.RemoveSavedPost(r.Context(), database.RemoveSavedPostParams{
+				UserID: user.ID,
+				PostID: post.ID,
+			})
+
+			return err
+		}
+
+		return usageError("items can be marked only as read, unread, saved or unsaved")
+	case "feed", "group":
+		if as != "read" {
+			return usageError("feeds and groups can be marked only as read")
+		}
+
+		before, err := strconv.ParseInt(r.Form.Get("before"), 10, 64)
+		if err != nil {
+			return usageError("before should be a unix timestamp")
+		}
+
+		params := database.MarkPostsReadForUserParams{
+			UserID: user.ID,
+			ReadAt: time.Now(),
+			Before: sql.NullTime{ Time: time.Unix(before, 0), Valid: before > 0 },
+		}
+
+		if r.Form.Get("mark") == "feed" {
+			feed, err := s.db.GetFeedByShortID(r.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			params.FeedID = uuid.NullUUID{ UUID: feed.ID, Valid: true }
+			_, err = s.db.MarkPostsReadForUser(r.Context(), params)
+
+			return err
+		}
+
+		// group 0 is the "Kindling" super group containing all feeds
+		if id == 0 {
+			_, err = s.db.MarkPostsReadForUser(r.Context(), params)
+
+			return err
+		}
+
+		feeds, err := s.db.GetFeverFeedsForUser(r.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, feverFeed := range feeds {
+			if !feverFeed.FolderShortID.Valid || feverFeed.FolderShortID.Int64 != id {
+				continue
+			}
+
+			feed, err := s.db.GetFeedByShortID(r.Context(), feverFeed.ShortID)
+			if err != nil {
+				return err
+			}
+
+			params.FeedID = uuid.NullUUID{ UUID: feed.ID, Valid: true }
+
+			_, err = s.db.MarkPostsReadForUser(r.Context(), params)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return usageError("mark should be item, feed or group")
+}
+
+func feverFeedsGroups(feeds []database.GetFeverFeedsForUserRow) []feverFeedsGroup {
+	feedIDs := map[int64][]int64{}
+	groupIDs := []int64{}
+
+	for _, feed := range feeds {
+		if !feed.FolderShortID.Valid {
+			continue
+		}
+
+		groupID := feed.FolderShortID.Int64
+		if _, exst := feedIDs[groupID]; !exst {
+			groupIDs = append(groupIDs, groupID)
+		}
+
+		feedIDs[groupID] = append(feedIDs[groupID], feed.ShortID)
+	}
+
+	result := []feverFeedsGroup{}
+	for _, groupID := range groupIDs {
+		result = append(result, feverFeedsGroup{ GroupID: groupID, FeedIDs: joinIDs(feedIDs[groupID]) })
+	}
+
+	return result
+}
+
+func feverBool(value bool) int {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+func joinIDs(ids []int64) string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, strconv.FormatInt(id, 10))
+	}
+
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"internal/database"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestFeverAPIKey(t *testing.T) {
+	tests := []struct {
+		name string
+		password string
+		want string
+	}{
+		{ name: "alice", password: "secret", want: "6f622058968bb90757e6c6ed79e5df81" },
+		{ name: "bob", password: "secret", want: "a5ab2b88c7de2711ffcb9c24fe8a545a" },
+		{ name: "alice", password: "", want: "770553c33dfa3a641da8312d60b3b9ef" },
+	}
+
+	for _, test := range tests {
+		got := feverAPIKey(test.name, test.password)
+		if got != test.want {
+			t.Errorf("feverAPIKey(%q, %q) = %s, want %s", test.name, test.password, got, test.want)
+		}
+	}
+}
+
+func feverRequest(form url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/fever/?api", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ParseForm()
+
+	return r
+}
+
+func TestFeverItemsParams(t *testing.T) {
+	user := database.User{ ID: uuid.New() }
+
+	tests := []struct {
+		name string
+		form url.Values
+		want database.GetFeverItemsForUserParams
+		wantErr bool
+	}{
+		{
+			name: "no parameters",
+			form: url.Values{},
+			want: database.GetFeverItemsForUserParams{ UserID: user.ID },
+		},
+		{
+			name: "since_id",
+			form: url.Values{ "since_id": { "42" } },
+			want: database.GetFeverItemsForUserParams{ UserID: user.ID, SinceID: sql.NullInt64{ Int64: 42, Valid: true } },
+		},
+		{
+			name: "max_id",
+			form: url.Values{ "max_id": { "7" } },
+			want: database.GetFeverItemsForUserParams{ UserID: user.ID, MaxID: sql.NullInt64{ Int64: 7, Valid: true } },
+		},
+		{
+			name: "with_ids are normalized",
+			form: url.Values{ "with_ids": { "1, 2,3" } },
+			want: database.GetFeverItemsForUserParams{ UserID: user.ID, WithIds: sql.NullString{ String: "1,2,3", Valid: true } },
+		},
+		{ name: "since_id isn't a number", form: url.Values{ "since_id": { "abc" } }, wantErr: true },
+		{ name: "max_id isn't a number", form: url.Values{ "max_id": { "1.5" } }, wantErr: true },
+		{ name: "with_ids aren't numbers", form: url.Values{ "with_ids": { "1,,2" } }, wantErr: true },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := feverItemsParams(feverRequest(test.form), user)
+			if test.wantErr {
+				if !errors.Is(err, errUsage) {
+					t.Errorf("feverItemsParams() error = %v, want usage error", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("feverItemsParams() error = %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("feverItemsParams() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFeverMarkInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		form url.Values
+	}{
+		{ name: "id isn't a number", form: url.Values{ "mark": { "item" }, "as": { "read" }, "id": { "abc" } } },
+		{ name: "unknown mark", form: url.Values{ "mark": { "post" }, "as": { "read" }, "id": { "1" } } },
+		{ name: "feed marked unread", form: url.Values{ "mark": { "feed" }, "as": { "unread" }, "id": { "1" }, "before": { "0" } } },
+		{ name: "group marked saved", form: url.Values{ "mark": { "group" }, "as": { "saved" }, "id": { "1" }, "before": { "0" } } },
+		{ name: "before isn't a timestamp", form: url.Values{ "mark": { "group" }, "as": { "read" }, "id": { "0" }, "before": { "yesterday" } } },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// invalid requests are refused before any query
+			s, _ := newFakeState(t, map[string]fakeResult{})
+
+			err := feverMark(s, feverRequest(test.form), database.User{ ID: uuid.New() })
+			if !errors.Is(err, errUsage) {
+				t.Errorf("feverMark() error = %v, want usage error", err)
+			}
+		})
+	}
+}
+
+func TestFeverMarkKindling(t *testing.T) {
+	s, db := newFakeState(t, map[string]fakeResult{
+		"MarkPostsReadForUser": { rowsAffected: 3 },
+	})
+
+	user := database.User{ ID: uuid.New() }
+
+	err := feverMark(s, feverRequest(url.Values{ "mark": { "group" }, "as": { "read" }, "id": { "0" }, "before": { "0" } }), user)
+	if err != nil {
+		t.Fatalf("feverMark() error = %v", err)
+	}
+
+	calls := db.called("MarkPostsReadForUser")
+	if len(calls) != 1 {
+		t.Fatalf("MarkPostsReadForUser called %d times, want once", len(calls))
+	}
+
+	// group 0 covers all feeds and before 0 all posts
+	if calls[0][0] != user.ID.String() || calls[0][2] != nil || calls[0][3] != nil {
+		t.Errorf("MarkPostsReadForUser called with %v, want all posts of the user", calls[0])
+	}
+}
+
+func TestFeverFeedsGroups(t *testing.T) {
+	feeds := []database.GetFeverFeedsForUserRow{
+		{ ShortID: 1, FolderShortID: sql.NullInt64{ Int64: 20, Valid: true } },
+		{ ShortID: 2 },
+		{ ShortID: 3, FolderShortID: sql.NullInt64{ Int64: 10, Valid: true } },
+		{ ShortID: 4, FolderShortID: sql.NullInt64{ Int64: 20, Valid: true } },
+	}
+
+	got := feverFeedsGroups(feeds)
+	want := []feverFeedsGroup{
+		{ GroupID: 20, FeedIDs: "1,4" },
+		{ GroupID: 10, FeedIDs: "3" },
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("feverFeedsGroups() = %+v, want %+v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("feverFeedsGroups()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFeverAPIAuth(t *testing.T) {
+	tests := []struct {
+		name string
+		target string
+		wantStatus int
+		wantAuth int
+	}{
+		{ name: "missing api parameter", target: "/fever/?api_key=x", wantStatus: http.StatusBadRequest },
+		{ name: "unknown api key", target: "/fever/?api&api_key=6f622058968bb90757e6c6ed79e5df81", wantStatus: http.StatusOK, wantAuth: 0 },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _ := newFakeState(t, map[string]fakeResult{
+				"GetUserByFeverAPIKey": {},
+			})
+
+			recorder := httptest.NewRecorder()
+			newServeMux(s).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, test.target, nil))
+
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+
+			if test.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response map[string]any
+
+			err := json.Unmarshal(recorder.Body.Bytes(), &response)
+			if err != nil {
+				t.Fatalf("response isn't json: %v", err)
+			}
+
+			if response["auth"] != float64(test.wantAuth) || response["api_version"] != float64(3) {
+				t.Errorf("response = %v, want auth %d", response, test.wantAuth)
+			}
+		})
+	}
+}
+
+func TestFeverMarkUnfollowedItem(t *testing.T) {
+	s, db := newFakeState(t, map[string]fakeResult{
+		"GetPostByShortIDForUser": {},
+	})
+
+	user := database.User{ ID: uuid.New() }
+
+	err := feverMark(s, feverRequest(url.Values{ "mark": { "item" }, "as": { "saved" }, "id": { "42" } }), user)
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("feverMark() of item outside of followed feeds error = %v, want not found", err)
+	}
+
+	calls := db.called("GetPostByShortIDForUser")
+	if len(calls) != 1 || calls[0][0] != int64(42) || calls[0][1] != user.ID.String() {
+		t.Errorf("GetPostByShortIDForUser called with %v, want item 42 of the user", calls)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func handlerFolder(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) < 1 {
+		return usageError("folder command expects a subcommand - create <folder>, add <feed-url> <folder>, unset <feed-url>, remove <folder> or list")
+	}
+
+	switch cmd.arguments[0] {
+	case "create":
+		if len(cmd.arguments) != 2 {
+			return usageError("there should be one argument for folder create command - folder name")
+		}
+
+		folder, err := s.db.CreateFolder(context.Background(), database.CreateFolderParams{
+			ID: uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID: currentUser.ID,
+			Name: cmd.arguments[1],
+		})
+		if err != nil {
+			slog.Error("error while creating folder", "error", err)
+
+			return err
+		}
+
+		fmt.Printf("successfully created folder: %s\n", folder.Name)
+	case "add":
+		if len(cmd.arguments) != 3 {
+			return usageError("there should be two arguments for folder add command - feed url and folder name")
+		}
+
+		feed, err := s.db.GetFeedByURL(context.Background(), cmd.arguments[1])
+		if err != nil {
+			slog.Error("error while retrieving feed to put into folder", "error", err)
+
+			return err
+		}
+
+		folder, err := s.db.GetFolderByName(context.Background(), database.GetFolderByNameParams{
+			UserID: currentUser.ID,
+			Name: cmd.arguments[2],
+		})
+		if err != nil {
+			slog.Error("no such folder", "name", cmd.arguments[2], "error", err)
+
+			return err
+		}
+
+		updated, err := s.db.SetFeedFollowFolder(context.Background(), database.SetFeedFollowFolderParams{
+			FolderID: uuid.NullUUID{ UUID: folder.ID, Valid: true },
+			UpdatedAt: time.Now(),
+			UserID: currentUser.ID,
+			FeedID: feed.ID,
+		})
+		if err != nil {
+			slog.Error("error while putting feed into folder", "error", err)
+
+			return err
+		}
+
+		if updated == 0 {
+			return notFoundError("you don't follow feed %s", feed.Url)
+		}
+
+		fmt.Printf("successfully put feed %s into folder %s\n", feed.Name, folder.Name)
+	case "unset":
+		if len(cmd.arguments) != 2 {
+			return usageError("there should be one argument for folder unset command - feed url")
+		}
+
+		feed, err := s.db.GetFeedByURL(context.Background(), cmd.arguments[1])
+		if err != nil {
+			slog.Error("error while retrieving feed to take out of folder", "error", err)
+
+			return err
+		}
+
+		updated, err := s.db.SetFeedFollowFolder(context.Background(), database.SetFeedFollowFolderParams{
+			FolderID: uuid.NullUUID{},
+			UpdatedAt: time.Now(),
+			UserID: currentUser.ID,
+			FeedID: feed.ID,
+		})
+		if err != nil {
+			slog.Error("error while taking feed out of folder", "error", err)
+
+			return err
+		}
+
+		if updated == 0 {
+			return notFoundError("you don't follow feed %s", feed.Url)
+		}
+
+		fmt.Printf("successfully took feed %s out of its folder\n", feed.Name)
+	case "remove":
+		if len(cmd.arguments) != 2 {
+			return usageError("there should be one argument for folder remove command - folder name")
+		}
+
+		removed, err := s.db.RemoveFolder(context.Background(), database.RemoveFolderParams{
+			UserID: currentUser.ID,
+			Name: cmd.arguments[1],
+		})
+		if err != nil {
+			slog.Error("error while removing folder", "error", err)
+
+			return err
+		}
+
+		if removed == 0 {
+			return notFoundError("no such folder")
+		}
+
+		fmt.Println("successfull folder remove, its feeds are left without folder")
+	case "list":
+		folders, err := s.db.GetFoldersForUser(context.Background(), currentUser.ID)
+		if err != nil {
+			slog.Error("error while retrieving folders", "error", err)
+
+			return err
+		}
+
+		for _, folder := range folders {
+			fmt.Printf("* %s\n", folder.Name)
+		}
+	default:
+		return usageError("no such folder subcommand - %s", cmd.arguments[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"internal/database"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestFolderUnset(t *testing.T) {
+	tests := []struct {
+		name string
+		updated int64
+		wantErr error
+	}{
+		{ name: "followed feed", updated: 1 },
+		{ name: "feed not followed", updated: 0, wantErr: errNotFound },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, db := newFakeState(t, map[string]fakeResult{
+				"GetFeedByURL": { rows: [][]driver.Value{ testFeed("https://example.com/feed") } },
+				"SetFeedFollowFolder": { rowsAffected: test.updated },
+			})
+
+			err := handlerFolder(s, command{ name: "folder", arguments: []string{ "unset", "https://example.com/feed" } }, database.User{ ID: uuid.New() })
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("handlerFolder() error = %v, want %v", err, test.wantErr)
+			}
+
+			calls := db.called("SetFeedFollowFolder")
+			if len(calls) != 1 || calls[0][0] != nil {
+				t.Errorf("SetFeedFollowFolder called with %v, want folder cleared", calls)
+			}
+		})
+	}
+}
+
+func TestExportUnknownFolder(t *testing.T) {
+	s, db := newFakeState(t, map[string]fakeResult{
+		"GetFolderByName": {},
+	})
+
+	err := handlerExport(s, command{ name: "export", arguments: []string{ "--folder", "news" } }, database.User{ ID: uuid.New() })
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("handlerExport() error = %v, want not found", err)
+	}
+
+	if calls := len(db.called("GetFeedFollowsForUser")); calls != 0 {
+		t.Errorf("GetFeedFollowsForUser called %d times, want nothing exported", calls)
+	}
+}
@@ -4,13 +4,23 @@ go 1.25.5
 
 require internal/config v1.0.0
 require internal/database v1.0.0
+require internal/feedgen v1.0.0
+require internal/metrics v1.0.0
+require internal/opml v1.0.0
 require internal/rss v1.0.0
 
 require (
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/lib/pq v1.10.9 // indirect
+	github.com/google/uuid v1.6.0
+	github.com/lib/pq v1.10.9
+	golang.org/x/crypto v0.54.0
+	golang.org/x/term v0.45.0
 )
 
+require golang.org/x/sys v0.47.0 // indirect
+
 replace internal/config => ./internal/config
 replace internal/database => ./internal/database
+replace internal/feedgen => ./internal/feedgen
+replace internal/metrics => ./internal/metrics
+replace internal/opml => ./internal/opml
 replace internal/rss => ./internal/rss
@@ -8,7 +8,20 @@ import (
 
 type Config struct {
 	DbUrl string `json:"db_url"`
-	CurrentUserName string `json:"current_user_name"`
+	SessionToken string `json:"session_token"`
+	SMTP *SMTPConfig `json:"smtp,omitempty"`
+	LogLevel string `json:"log_level,omitempty"`
+	LogFormat string `json:"log_format,omitempty"`
+}
+
+// SMTPConfig is the mail server digests are sent through,
+// username may be left empty for servers without authentication
+type SMTPConfig struct {
+	Host string `json:"host"`
+	Port int `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From string `json:"from"`
 }
 
 func Read() (*Config, error) {
@@ -32,23 +45,28 @@ func Read() (*Config, error) {
 	return &payload, nil
 }
 
-func (c Config) SetUser(userName string) error {
+func (c *Config) SetSession(sessionToken string) error {
 	filePath, err := getConfigFilePath()
 	if err != nil {
-		return fmt.Errorf("error while setting user")
+		return fmt.Errorf("error while setting session")
 	}
 
-	file, err := os.Create(filePath)
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
-		return fmt.Errorf("error while setting user")
+		return fmt.Errorf("error while setting session")
 	}
 	defer file.Close()
 
-	c.CurrentUserName = userName
+	// config holds the session token, so keep it private even if it was created earlier
+	if err := file.Chmod(0600); err != nil {
+		return fmt.Errorf("error while setting session")
+	}
+
+	c.SessionToken = sessionToken
 
 	encoder := json.NewEncoder(file)
 	if err := encoder.Encode(c); err != nil {
-		return fmt.Errorf("error while setting user")
+		return fmt.Errorf("error while setting session")
 	}
 
 	return nil
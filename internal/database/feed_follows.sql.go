@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,10 +23,10 @@ WITH inserted_feed_follow AS (
         $4,
         $5
     )
-    RETURNING id, created_at, updated_at, user_id, feed_id
+    RETURNING id, created_at, updated_at, user_id, feed_id, folder_id, custom_name, priority, notify, hidden
 )
 SELECT
-    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id,
+    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.folder_id, inserted_feed_follow.custom_name, inserted_feed_follow.priority, inserted_feed_follow.notify, inserted_feed_follow.hidden,
     feeds.name AS feed_name,
     users.name AS user_name
 FROM inserted_feed_follow
@@ -44,13 +45,18 @@ type CreateFeedFollowParams struct {
 }
 
 type CreateFeedFollowRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+	FolderID   uuid.NullUUID
+	CustomName sql.NullString
+	Priority   int32
+	Notify     bool
+	Hidden     bool
+	FeedName   string
+	UserName   string
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
@@ -68,33 +74,80 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.FolderID,
+		&i.CustomName,
+		&i.Priority,
+		&i.Notify,
+		&i.Hidden,
 		&i.FeedName,
 		&i.UserName,
 	)
 	return i, err
 }
 
+const getFeedFollow = `-- name: GetFeedFollow :one
+SELECT id, created_at, updated_at, user_id, feed_id, folder_id, custom_name, priority, notify, hidden FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type GetFeedFollowParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+}
+
+func (q *Queries) GetFeedFollow(ctx context.Context, arg GetFeedFollowParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollow, arg.UserID, arg.FeedID)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FolderID,
+		&i.CustomName,
+		&i.Priority,
+		&i.Notify,
+		&i.Hidden,
+	)
+	return i, err
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT
-    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id,
+    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.folder_id, feed_follows.custom_name, feed_follows.priority, feed_follows.notify, feed_follows.hidden,
     feeds.name AS feed_name,
-    users.name AS user_name
+    COALESCE(feed_follows.custom_name, feeds.name) AS display_name,
+    feeds.url AS feed_url,
+    users.name AS user_name,
+    folders.name AS folder_name
 FROM feed_follows
 INNER JOIN feeds
 ON feed_follows.feed_id = feeds.id
 INNER JOIN users
 ON feed_follows.user_id = users.id
+LEFT JOIN folders
+ON feed_follows.folder_id = folders.id
 WHERE feed_follows.user_id = $1
+ORDER BY folders.name NULLS FIRST, feed_follows.priority DESC, COALESCE(feed_follows.custom_name, feeds.name)
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	FeedID      uuid.UUID
+	FolderID    uuid.NullUUID
+	CustomName  sql.NullString
+	Priority    int32
+	Notify      bool
+	Hidden      bool
+	FeedName    string
+	DisplayName string
+	FeedUrl     string
+	UserName    string
+	FolderName  sql.NullString
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -112,8 +165,16 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.FolderID,
+			&i.CustomName,
+			&i.Priority,
+			&i.Notify,
+			&i.Hidden,
 			&i.FeedName,
+			&i.DisplayName,
+			&i.FeedUrl,
 			&i.UserName,
+			&i.FolderName,
 		); err != nil {
 			return nil, err
 		}
@@ -142,3 +203,35 @@ func (q *Queries) RemoveFeedFollowsForUser(ctx context.Context, arg RemoveFeedFo
 	_, err := q.db.ExecContext(ctx, removeFeedFollowsForUser, arg.FeedID, arg.UserID)
 	return err
 }
+
+const updateFeedFollowSettings = `-- name: UpdateFeedFollowSettings :execrows
+UPDATE feed_follows
+SET custom_name = $1, priority = $2, notify = $3, hidden = $4, updated_at = $5
+WHERE user_id = $6 AND feed_id = $7
+`
+
+type UpdateFeedFollowSettingsParams struct {
+	CustomName sql.NullString
+	Priority   int32
+	Notify     bool
+	Hidden     bool
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+}
+
+func (q *Queries) UpdateFeedFollowSettings(ctx context.Context, arg UpdateFeedFollowSettingsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateFeedFollowSettings,
+		arg.CustomName,
+		arg.Priority,
+		arg.Notify,
+		arg.Hidden,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
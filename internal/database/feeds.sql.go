@@ -22,7 +22,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, short_id
 `
 
 type CreateFeedParams struct {
@@ -52,12 +52,47 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.ShortID,
+	)
+	return i, err
+}
+
+const deleteFeed = `-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeed, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getFeedByShortID = `-- name: GetFeedByShortID :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, short_id FROM feeds
+WHERE short_id = $1
+`
+
+func (q *Queries) GetFeedByShortID(ctx context.Context, shortID int64) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByShortID, shortID)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.ShortID,
 	)
 	return i, err
 }
 
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, short_id FROM feeds
 WHERE url = $1
 `
 
@@ -72,12 +107,58 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.ShortID,
+	)
+	return i, err
+}
+
+const getFeedStats = `-- name: GetFeedStats :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.feed_id = $1) AS follows_count,
+    (SELECT COUNT(*) FROM posts WHERE posts.feed_id = $1) AS posts_count,
+    (
+        SELECT COUNT(*) FROM user_saved_posts
+        INNER JOIN posts
+        ON user_saved_posts.post_id = posts.id
+        WHERE posts.feed_id = $1
+    ) AS starred_count,
+    (
+        SELECT COUNT(*) FROM user_saved_posts
+        INNER JOIN posts
+        ON user_saved_posts.post_id = posts.id
+        WHERE posts.feed_id = $1 AND user_saved_posts.note IS NOT NULL
+    ) AS noted_count,
+    (
+        SELECT COUNT(*) FROM post_tags
+        INNER JOIN posts
+        ON post_tags.post_id = posts.id
+        WHERE posts.feed_id = $1
+    ) AS tags_count
+`
+
+type GetFeedStatsRow struct {
+	FollowsCount int64
+	PostsCount   int64
+	StarredCount int64
+	NotedCount   int64
+	TagsCount    int64
+}
+
+func (q *Queries) GetFeedStats(ctx context.Context, feedID uuid.UUID) (GetFeedStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedStats, feedID)
+	var i GetFeedStatsRow
+	err := row.Scan(
+		&i.FollowsCount,
+		&i.PostsCount,
+		&i.StarredCount,
+		&i.NotedCount,
+		&i.TagsCount,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, short_id FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -97,6 +178,7 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.ShortID,
 		); err != nil {
 			return nil, err
 		}
@@ -112,13 +194,19 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.short_id FROM feeds
+LEFT JOIN websub_subscriptions
+ON feeds.id = websub_subscriptions.feed_id AND websub_subscriptions.state = 'active'
+WHERE websub_subscriptions.lease_expires_at IS NULL
+OR websub_subscriptions.lease_expires_at <= $1::timestamp
+OR feeds.last_fetched_at IS NULL
+OR feeds.last_fetched_at <= $1::timestamp - INTERVAL '1 day'
+ORDER BY feeds.last_fetched_at NULLS FIRST
 LIMIT 1
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
+func (q *Queries) GetNextFeedToFetch(ctx context.Context, now time.Time) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getNextFeedToFetch, now)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -128,10 +216,24 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.ShortID,
 	)
 	return i, err
 }
 
+const getOldestFeedFetch = `-- name: GetOldestFeedFetch :one
+SELECT COALESCE(last_fetched_at, created_at) AS fetched_at FROM feeds
+ORDER BY last_fetched_at NULLS FIRST, created_at
+LIMIT 1
+`
+
+func (q *Queries) GetOldestFeedFetch(ctx context.Context) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getOldestFeedFetch)
+	var fetched_at time.Time
+	err := row.Scan(&fetched_at)
+	return fetched_at, err
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :exec
 UPDATE feeds
 SET last_fetched_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
@@ -142,3 +244,54 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
 	return err
 }
+
+const renameFeed = `-- name: RenameFeed :exec
+UPDATE feeds
+SET name = $1, updated_at = $2
+WHERE id = $3
+`
+
+type RenameFeedParams struct {
+	Name      string
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) RenameFeed(ctx context.Context, arg RenameFeedParams) error {
+	_, err := q.db.ExecContext(ctx, renameFeed, arg.Name, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setFeedURL = `-- name: SetFeedURL :exec
+UPDATE feeds
+SET url = $1, updated_at = $2, last_fetched_at = NULL
+WHERE id = $3
+`
+
+type SetFeedURLParams struct {
+	Url       string
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) SetFeedURL(ctx context.Context, arg SetFeedURLParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedURL, arg.Url, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const transferFeed = `-- name: TransferFeed :exec
+UPDATE feeds
+SET user_id = $1, updated_at = $2
+WHERE id = $3
+`
+
+type TransferFeedParams struct {
+	UserID    uuid.UUID
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) TransferFeed(ctx context.Context, arg TransferFeedParams) error {
+	_, err := q.db.ExecContext(ctx, transferFeed, arg.UserID, arg.UpdatedAt, arg.ID)
+	return err
+}
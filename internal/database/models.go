@@ -11,6 +11,49 @@ import (
 	"github.com/google/uuid"
 )
 
+type Alert struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Query     string
+}
+
+type AlertHit struct {
+	AlertID   uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ApiKey struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UserID     uuid.UUID
+	Name       string
+	KeyHash    string
+	LastUsedAt sql.NullTime
+}
+
+type AuditLog struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.NullUUID
+	UserName  sql.NullString
+	Command   string
+	Arguments string
+	Outcome   string
+	Error     sql.NullString
+}
+
+type DigestSubscription struct {
+	UserID     uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Email      string
+	Frequency  string
+	LastSentAt sql.NullTime
+}
+
 type Feed struct {
 	ID            uuid.UUID
 	CreatedAt     time.Time
@@ -19,30 +62,163 @@ type Feed struct {
 	Url           string
 	UserID        uuid.UUID
 	LastFetchedAt sql.NullTime
+	ShortID       int64
 }
 
 type FeedFollow struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+	FolderID   uuid.NullUUID
+	CustomName sql.NullString
+	Priority   int32
+	Notify     bool
+	Hidden     bool
+}
+
+type FeverCredential struct {
+	UserID    uuid.UUID
+	ApiKey    string
+	CreatedAt time.Time
+}
+
+type Folder struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	UserID    uuid.UUID
-	FeedID    uuid.UUID
+	Name      string
+	ShortID   int64
+}
+
+type HiddenPost struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type PasswordReset struct {
+	UserID    uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
 }
 
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       sql.NullString
-	Url         string
-	Description sql.NullString
-	PublishedAt time.Time
-	FeedID      uuid.UUID
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        sql.NullString
+	Url          string
+	Description  sql.NullString
+	PublishedAt  time.Time
+	FeedID       uuid.UUID
+	SearchVector interface{}
+	Author       sql.NullString
+	Categories   sql.NullString
+	ShortID      int64
 }
 
-type User struct {
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type PostTag struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	Tag       string
+	CreatedAt time.Time
+}
+
+type RoleChange struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	ChangedBy uuid.NullUUID
+	UserID    uuid.UUID
+	Role      string
+}
+
+type Rule struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Name      string
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Field     string
+	MatchType string
+	Pattern   string
+	Action    string
+	Tag       sql.NullString
+}
+
+type Session struct {
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UserID    uuid.UUID
+}
+
+type TimelineToken struct {
+	UserID    uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+}
+
+type User struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	PasswordHash sql.NullString
+	Role         string
+}
+
+type UserSavedPost struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Note      sql.NullString
+}
+
+type Webhook struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	UserID          uuid.UUID
+	Url             string
+	Secret          string
+	FeedID          uuid.NullUUID
+	FolderID        uuid.NullUUID
+	PayloadTemplate sql.NullString
+}
+
+type WebhookDelivery struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	WebhookID     uuid.UUID
+	PostID        uuid.UUID
+	Status        string
+	Attempts      int32
+	NextAttemptAt time.Time
+	ResponseCode  sql.NullInt32
+	LastError     sql.NullString
+}
+
+type WebsubSubscription struct {
+	FeedID         uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	HubUrl         string
+	TopicUrl       string
+	Secret         string
+	State          string
+	RequestedAt    sql.NullTime
+	LeaseExpiresAt sql.NullTime
+	CallbackToken  string
 }
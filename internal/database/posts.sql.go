@@ -14,7 +14,7 @@ import (
 )
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories)
 VALUES (
     $1,
     $2,
@@ -23,9 +23,12 @@ VALUES (
     $5,
     $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10
 )
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+ON CONFLICT (url) DO NOTHING
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, search_vector, author, categories, short_id
 `
 
 type CreatePostParams struct {
@@ -37,6 +40,8 @@ type CreatePostParams struct {
 	Description sql.NullString
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Author      sql.NullString
+	Categories  sql.NullString
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -49,6 +54,8 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Author,
+		arg.Categories,
 	)
 	var i Post
 	err := row.Scan(
@@ -60,34 +67,221 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.SearchVector,
+		&i.Author,
+		&i.Categories,
+		&i.ShortID,
+	)
+	return i, err
+}
+
+const getAllPostsForUser = `-- name: GetAllPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.search_vector, posts.author, posts.categories, posts.short_id FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY posts.published_at DESC
+`
+
+func (q *Queries) GetAllPostsForUser(ctx context.Context, userID uuid.UUID) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getAllPostsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SearchVector,
+			&i.Author,
+			&i.Categories,
+			&i.ShortID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostByIdForUser = `-- name: GetPostByIdForUser :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.search_vector, posts.author, posts.categories, posts.short_id FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE posts.id = $1 AND feed_follows.user_id = $2
+`
+
+type GetPostByIdForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetPostByIdForUser(ctx context.Context, arg GetPostByIdForUserParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByIdForUser, arg.ID, arg.UserID)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.SearchVector,
+		&i.Author,
+		&i.Categories,
+		&i.ShortID,
+	)
+	return i, err
+}
+
+const getPostByShortIDForUser = `-- name: GetPostByShortIDForUser :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.search_vector, posts.author, posts.categories, posts.short_id FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE posts.short_id = $1 AND feed_follows.user_id = $2
+`
+
+type GetPostByShortIDForUserParams struct {
+	ShortID int64
+	UserID  uuid.UUID
+}
+
+func (q *Queries) GetPostByShortIDForUser(ctx context.Context, arg GetPostByShortIDForUserParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByShortIDForUser, arg.ShortID, arg.UserID)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.SearchVector,
+		&i.Author,
+		&i.Categories,
+		&i.ShortID,
 	)
 	return i, err
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts
-WHERE feed_id IN (
-    SELECT id FROM feeds
-    WHERE user_id = $1
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.search_vector, posts.author, posts.categories, posts.short_id,
+    COALESCE(feed_follows.custom_name, feeds.name) AS feed_name,
+    feeds.url AS feed_url,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = $1
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_saved_posts
+        WHERE user_saved_posts.post_id = posts.id AND user_saved_posts.user_id = $1
+    ) AS is_saved
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+AND ($2::text IS NULL OR feeds.url = $2::text)
+AND (NOT feed_follows.hidden OR $2::text IS NOT NULL)
+AND ($3::timestamp IS NULL OR posts.published_at >= $3::timestamp)
+AND ($4::timestamp IS NULL OR posts.published_at < $4::timestamp)
+AND (NOT $5::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = $1
+))
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.post_id = posts.id AND hidden_posts.user_id = $1
 )
-ORDER BY published_at DESC
-LIMIT $2
+AND ($6::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id AND post_tags.user_id = $1 AND post_tags.tag = $6::text
+))
+AND ($7::text IS NULL OR feed_follows.folder_id IN (
+    SELECT id FROM folders
+    WHERE folders.user_id = $1 AND folders.name = $7::text
+))
+ORDER BY
+    CASE WHEN $8::boolean THEN posts.published_at END ASC,
+    CASE WHEN NOT $8::boolean THEN posts.published_at END DESC,
+    posts.id
+LIMIT $9
+OFFSET $10
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID      uuid.UUID
+	FeedUrl     sql.NullString
+	Since       sql.NullTime
+	Until       sql.NullTime
+	UnreadOnly  bool
+	Tag         sql.NullString
+	Folder      sql.NullString
+	OldestFirst bool
+	PostLimit   int32
+	PostOffset  int32
+}
+
+type GetPostsForUserRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        sql.NullString
+	Url          string
+	Description  sql.NullString
+	PublishedAt  time.Time
+	FeedID       uuid.UUID
+	SearchVector interface{}
+	Author       sql.NullString
+	Categories   sql.NullString
+	ShortID      int64
+	FeedName     string
+	FeedUrl      string
+	IsRead       bool
+	IsSaved      bool
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.FeedUrl,
+		arg.Since,
+		arg.Until,
+		arg.UnreadOnly,
+		arg.Tag,
+		arg.Folder,
+		arg.OldestFirst,
+		arg.PostLimit,
+		arg.PostOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []GetPostsForUserRow
 	for rows.Next() {
-		var i Post
+		var i GetPostsForUserRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -97,6 +291,95 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.SearchVector,
+			&i.Author,
+			&i.Categories,
+			&i.ShortID,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.IsRead,
+			&i.IsSaved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchPostsForUser = `-- name: SearchPostsForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    COALESCE(feed_follows.custom_name, feeds.name) AS feed_name,
+    ts_rank(posts.search_vector, websearch_to_tsquery('english', $1::text)) AS rank,
+    ts_headline('english', coalesce(posts.description, ''), websearch_to_tsquery('english', $1::text), 'StartSel=**, StopSel=**, MaxFragments=2') AS snippet
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE feed_follows.user_id = $2
+AND posts.search_vector @@ websearch_to_tsquery('english', $1::text)
+AND ($3::text IS NULL OR feeds.url = $3::text)
+AND (NOT feed_follows.hidden OR $3::text IS NOT NULL)
+AND ($4::timestamp IS NULL OR posts.published_at >= $4::timestamp)
+AND ($5::timestamp IS NULL OR posts.published_at < $5::timestamp)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT $6
+`
+
+type SearchPostsForUserParams struct {
+	Query     string
+	UserID    uuid.UUID
+	FeedUrl   sql.NullString
+	Since     sql.NullTime
+	Until     sql.NullTime
+	PostLimit int32
+}
+
+type SearchPostsForUserRow struct {
+	ID          uuid.UUID
+	Title       sql.NullString
+	Url         string
+	PublishedAt time.Time
+	FeedName    string
+	Rank        float32
+	Snippet     string
+}
+
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUserParams) ([]SearchPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsForUser,
+		arg.Query,
+		arg.UserID,
+		arg.FeedUrl,
+		arg.Since,
+		arg.Until,
+		arg.PostLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsForUserRow
+	for rows.Next() {
+		var i SearchPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.Rank,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,183 @@
+package feedgen
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Feed is a format independent description of a generated feed,
+// it can be written both as Atom and as RSS 2.0
+type Feed struct {
+	ID       string
+	Title    string
+	Subtitle string
+	Author   string
+	Link     string
+	SelfLink string
+	Updated  time.Time
+	Entries  []Entry
+}
+
+type Entry struct {
+	ID         string
+	Title      string
+	Link       string
+	Summary    string
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   atomAuthor  `xml:"author"`
+	Link     []atomLink  `xml:"link"`
+	Entry    []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomSummary struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string         `xml:"id"`
+	Title     string         `xml:"title"`
+	Link      atomLink       `xml:"link"`
+	Published string         `xml:"published"`
+	Updated   string         `xml:"updated"`
+	Author    *atomAuthor    `xml:"author"`
+	Category  []atomCategory `xml:"category"`
+	Summary   atomSummary    `xml:"summary"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	DC      string   `xml:"xmlns:dc,attr"`
+	Channel struct {
+		Title         string    `xml:"title"`
+		Link          string    `xml:"link"`
+		Description   string    `xml:"description"`
+		LastBuildDate string    `xml:"lastBuildDate"`
+		Item          []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Text        string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Category    []string `xml:"category"`
+}
+
+func (feed *Feed) WriteAtom(w io.Writer) error {
+	doc := atomFeed{
+		ID: feed.ID,
+		Title: feed.Title,
+		Subtitle: feed.Subtitle,
+		Updated: feed.Updated.UTC().Format(time.RFC3339),
+		Author: atomAuthor{ Name: feed.Author },
+	}
+
+	if feed.SelfLink != "" {
+		doc.Link = append(doc.Link, atomLink{ Rel: "self", Type: "application/atom+xml", Href: feed.SelfLink })
+	}
+
+	if feed.Link != "" {
+		doc.Link = append(doc.Link, atomLink{ Rel: "alternate", Href: feed.Link })
+	}
+
+	for _, entry := range feed.Entries {
+		item := atomEntry{
+			ID: entry.ID,
+			Title: entry.Title,
+			Link: atomLink{ Rel: "alternate", Href: entry.Link },
+			Published: entry.Published.UTC().Format(time.RFC3339),
+			Updated: entry.Updated.UTC().Format(time.RFC3339),
+			Summary: atomSummary{ Type: "html", Text: entry.Summary },
+		}
+
+		if entry.Author != "" {
+			item.Author = &atomAuthor{ Name: entry.Author }
+		}
+
+		for _, category := range entry.Categories {
+			item.Category = append(item.Category, atomCategory{ Term: category })
+		}
+
+		doc.Entry = append(doc.Entry, item)
+	}
+
+	return write(w, doc)
+}
+
+func (feed *Feed) WriteRSS(w io.Writer) error {
+	doc := rssFeed{ Version: "2.0", DC: "http://purl.org/dc/elements/1.1/" }
+
+	doc.Channel.Title = feed.Title
+	doc.Channel.Link = feed.Link
+	doc.Channel.Description = feed.Subtitle
+	doc.Channel.LastBuildDate = feed.Updated.Format(time.RFC1123Z)
+
+	for _, entry := range feed.Entries {
+		doc.Channel.Item = append(doc.Channel.Item, rssItem{
+			Title: entry.Title,
+			Link: entry.Link,
+			Description: entry.Summary,
+			GUID: rssGUID{ IsPermaLink: false, Text: entry.ID },
+			PubDate: entry.Published.Format(time.RFC1123Z),
+			Creator: entry.Author,
+			Category: entry.Categories,
+		})
+	}
+
+	return write(w, doc)
+}
+
+func write(w io.Writer, doc any) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
@@ -0,0 +1,3 @@
+module feedgen
+
+go 1.25.5
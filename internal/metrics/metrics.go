@@ -0,0 +1,275 @@
+// Package metrics implements the few metric types gator needs and writes them
+// in the Prometheus text exposition format
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram buckets in seconds suited for network and database latencies
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds metrics in the order they were created
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteText writes all metrics in the text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	buffered := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(buffered)
+	}
+
+	return buffered.Flush()
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// vec keeps one value per combination of label values
+type vec[T any] struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series[T]
+}
+
+type series[T any] struct {
+	labelValues []string
+	value       T
+}
+
+func newVec[T any](name string, help string, labels []string) vec[T] {
+	return vec[T]{name: name, help: help, labels: labels, series: map[string]*series[T]{}}
+}
+
+// with returns series for label values, it must be called with mu held
+func (v *vec[T]) with(labelValues []string, init func() T) *series[T] {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	s, found := v.series[key]
+	if !found {
+		s = &series[T]{labelValues: append([]string(nil), labelValues...), value: init()}
+		v.series[key] = s
+	}
+
+	return s
+}
+
+// sorted returns series ordered by label values so that output is stable
+func (v *vec[T]) sorted() []*series[T] {
+	result := make([]*series[T], 0, len(v.series))
+	for _, s := range v.series {
+		result = append(result, s)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Join(result[i].labelValues, "\xff") < strings.Join(result[j].labelValues, "\xff")
+	})
+
+	return result
+}
+
+func (v *vec[T]) writeHeader(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, escapeHelp(v.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, kind)
+}
+
+// writeValues writes series holding a single value, used by counters and gauges
+func writeValues(w *bufio.Writer, v *vec[float64], kind string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.writeHeader(w, kind)
+
+	// metrics without labels are reported as zero before they are first updated
+	if len(v.labels) == 0 {
+		v.with(nil, func() float64 { return 0 })
+	}
+
+	for _, s := range v.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", v.name, formatLabels(v.labels, s.labelValues, "", ""), formatValue(s.value))
+	}
+}
+
+// Counter is a value that only goes up, optionally split by labels
+type Counter struct {
+	vec[float64]
+}
+
+func (r *Registry) NewCounter(name string, help string, labels ...string) *Counter {
+	c := &Counter{vec: newVec[float64](name, help, labels)}
+	r.register(c)
+
+	return c
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(value float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.with(labelValues, func() float64 { return 0 }).value += value
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	writeValues(w, &c.vec, "counter")
+}
+
+// Histogram counts observations in cumulative buckets
+type Histogram struct {
+	vec[*histogramValue]
+	buckets []float64
+}
+
+type histogramValue struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (r *Registry) NewHistogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{vec: newVec[*histogramValue](name, help, labels), buckets: buckets}
+	r.register(h)
+
+	return h
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.with(labelValues, func() *histogramValue {
+		return &histogramValue{counts: make([]uint64, len(h.buckets))}
+	})
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.value.counts[i]++
+		}
+	}
+
+	s.value.count++
+	s.value.sum += value
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.writeHeader(w, "histogram")
+
+	for _, s := range h.sorted() {
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, s.labelValues, "le", formatValue(bound)), s.value.counts[i])
+		}
+
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, s.labelValues, "le", "+Inf"), s.value.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, s.labelValues, "", ""), formatValue(s.value.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, s.labelValues, "", ""), s.value.count)
+	}
+}
+
+// Gauge is a value that can go up and down, optionally split by labels
+type Gauge struct {
+	vec[float64]
+}
+
+func (r *Registry) NewGauge(name string, help string, labels ...string) *Gauge {
+	g := &Gauge{vec: newVec[float64](name, help, labels)}
+	r.register(g)
+
+	return g
+}
+
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.with(labelValues, func() float64 { return 0 }).value = value
+}
+
+func (g *Gauge) write(w *bufio.Writer) {
+	writeValues(w, &g.vec, "gauge")
+}
+
+func formatLabels(names []string, values []string, extraName string, extraValue string) string {
+	pairs := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		pairs = append(pairs, name+`="`+labelEscaper.Replace(values[i])+`"`)
+	}
+
+	if extraName != "" {
+		pairs = append(pairs, extraName+`="`+labelEscaper.Replace(extraValue)+`"`)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
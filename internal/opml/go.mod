@@ -0,0 +1,3 @@
+module opml
+
+go 1.25.5
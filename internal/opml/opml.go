@@ -0,0 +1,63 @@
+package opml
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated"`
+	} `xml:"head"`
+	Body struct {
+		Outline []Outline `xml:"outline"`
+	} `xml:"body"`
+}
+
+type Outline struct {
+	Type    string    `xml:"type,attr,omitempty"`
+	Text    string    `xml:"text,attr"`
+	Title   string    `xml:"title,attr,omitempty"`
+	XMLURL  string    `xml:"xmlUrl,attr,omitempty"`
+	Outline []Outline `xml:"outline"`
+}
+
+func New(title string) *OPML {
+	doc := OPML{ Version: "2.0" }
+
+	doc.Head.Title = title
+	doc.Head.DateCreated = time.Now().Format(time.RFC1123Z)
+
+	return &doc
+}
+
+func FeedOutline(name string, url string) Outline {
+	return Outline{ Type: "rss", Text: name, Title: name, XMLURL: url }
+}
+
+func FolderOutline(name string, feeds []Outline) Outline {
+	return Outline{ Text: name, Title: name, Outline: feeds }
+}
+
+func (doc *OPML) Write(w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
@@ -3,9 +3,12 @@ package rss
 import (
 	"context"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"strings"
 )
 
 type RSSFeed struct {
@@ -15,16 +18,38 @@ type RSSFeed struct {
 		Description string    `xml:"description"`
 		Item        []RSSItem `xml:"item"`
 	} `xml:"channel"`
+
+	// Hub and Self are WebSub links advertised by the feed
+	// either in Link headers or in atom:link elements
+	Hub  string `xml:"-"`
+	Self string `xml:"-"`
+}
+
+type atomLinks struct {
+	Channel struct {
+		Link []struct {
+			Rel  string `xml:"rel,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"http://www.w3.org/2005/Atom link"`
+	} `xml:"channel"`
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Category    []string `xml:"category"`
 }
 
-func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// ErrInvalidFeed is returned for documents that can't be parsed as RSS
+var ErrInvalidFeed = errors.New("invalid feed")
+
+// FetchFeed downloads and parses the feed with client, letting callers
+// instrument or limit requests through its transport
+func FetchFeed(ctx context.Context, client *http.Client, feedURL string) (*RSSFeed, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
 	if err != nil {
 		return nil, err
@@ -32,21 +57,64 @@ func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 
 	req.Header.Set("User-Agent", "gator")
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("feed responded with %s", res.Status)
+	}
 
 	resBody, err := io.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
 	}
 
+	feed, err := ParseFeed(resBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Link headers take precedence over links in the document
+	for _, header := range res.Header.Values("Link") {
+		for _, link := range strings.Split(header, ",") {
+			href, params, found := strings.Cut(link, ";")
+			if !found {
+				continue
+			}
+
+			href = strings.Trim(strings.TrimSpace(href), "<>")
+
+			for _, param := range strings.Split(params, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if name != "rel" {
+					continue
+				}
+
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					switch rel {
+					case "hub":
+						feed.Hub = href
+					case "self":
+						feed.Self = href
+					}
+				}
+			}
+		}
+	}
+
+	return feed, nil
+}
+
+// ParseFeed parses RSS document, used both for fetched feeds and for content pushed by WebSub hubs
+func ParseFeed(data []byte) (*RSSFeed, error) {
 	feed := RSSFeed{}
 
-	err = xml.Unmarshal(resBody, &feed)
+	err := xml.Unmarshal(data, &feed)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFeed, err)
 	}
 
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
@@ -57,5 +125,21 @@ func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 		item.Description = html.UnescapeString(item.Description)
 	}
 
+	links := atomLinks{}
+
+	err = xml.Unmarshal(data, &links)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFeed, err)
+	}
+
+	for _, link := range links.Channel.Link {
+		switch link.Rel {
+		case "hub":
+			feed.Hub = link.Href
+		case "self":
+			feed.Self = link.Href
+		}
+	}
+
 	return &feed, nil
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"internal/config"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// setupLogger makes slog log to stderr so that logs don't mix with output of commands,
+// level and format come from log_level and log_format of the config and can be
+// overridden by GATOR_LOG_LEVEL and GATOR_LOG_FORMAT environment variables
+func setupLogger(cfg *config.Config) error {
+	level := cfg.LogLevel
+	if value := os.Getenv("GATOR_LOG_LEVEL"); value != "" {
+		level = value
+	}
+
+	format := cfg.LogFormat
+	if value := os.Getenv("GATOR_LOG_FORMAT"); value != "" {
+		format = value
+	}
+
+	var options slog.HandlerOptions
+
+	if level != "" {
+		var slogLevel slog.Level
+
+		err := slogLevel.UnmarshalText([]byte(level))
+		if err != nil {
+			return usageError("log level should be one of debug, info, warn or error, got %s", level)
+		}
+
+		options.Level = slogLevel
+	}
+
+	var handler slog.Handler
+
+	switch strings.ToLower(format) {
+	case "", "text":
+		handler = slog.NewTextHandler(os.Stderr, &options)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, &options)
+	default:
+		return usageError("log format should be either text or json, got %s", format)
+	}
+
+	// this also sends messages of the standard log package through the handler
+	slog.SetDefault(slog.New(handler))
+
+	return nil
+}
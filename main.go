@@ -1,23 +1,32 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	"internal/config"
 	"internal/database"
 	"internal/rss"
+	"io"
+	"log/slog"
 	"os"
-	"strconv"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type state struct {
 	cfg *config.Config
 	db *database.Queries
+	sqlDB *sql.DB
 }
 
 type command struct {
@@ -41,6 +50,13 @@ func main() {
 
 	mainState.cfg = cfg
 
+	err = setupLogger(cfg)
+	if err != nil {
+		fmt.Println(err)
+
+		os.Exit(exitCode(err))
+	}
+
 	db, err := sql.Open("postgres", cfg.DbUrl)
 	if err != nil {
 		fmt.Println(err)
@@ -48,25 +64,58 @@ func main() {
 		os.Exit(1)
 	}
 
-	mainState.db = database.New(db)
+	mainState.db = database.New(timedDB{ DBTX: db })
+	mainState.sqlDB = db
 
 	commandsMap := commands { commands: make(map[string]func(*state, command) error) }
-	commandsMap.register("register", handlerRegister)
-	commandsMap.register("login", handlerLogin)
-	commandsMap.register("reset", handlerReset)
+	commandsMap.register("register", middlewareAudit(handlerRegister))
+	commandsMap.register("login", middlewareAudit(handlerLogin))
+	commandsMap.register("logout", middlewareAudit(handlerLogout))
+	commandsMap.register("passwd", middlewareAudit(middlewareLoggedIn(handlerPasswd)))
+	commandsMap.register("resetpassword", middlewareAudit(handlerResetPassword))
+	commandsMap.register("reset", middlewareAudit(middlewareAdmin(handlerReset)))
 	commandsMap.register("users", handlerUsers)
+	commandsMap.register("deleteuser", middlewareAudit(middlewareLoggedIn(handlerDeleteUser)))
+	commandsMap.register("renameuser", middlewareAudit(middlewareLoggedIn(handlerRenameUser)))
+	commandsMap.register("whoami", middlewareLoggedIn(handlerWhoami))
+	commandsMap.register("grant", middlewareAudit(middlewareAdmin(handlerGrant)))
+	commandsMap.register("revoke", middlewareAudit(middlewareAdmin(handlerRevoke)))
+	commandsMap.register("roles", middlewareAdmin(handlerRoles))
+	commandsMap.register("audit", middlewareAdmin(handlerAudit))
 	commandsMap.register("agg", middlewareLoggedIn(handlerAggregate))
-	commandsMap.register("addfeed", middlewareLoggedIn(handlerAddFeed))
+	commandsMap.register("addfeed", middlewareAudit(middlewareLoggedIn(handlerAddFeed)))
 	commandsMap.register("feeds", handlerFeeds)
-	commandsMap.register("follow", middlewareLoggedIn(handlerFollow))
+	commandsMap.register("rmfeed", middlewareAudit(middlewareLoggedIn(handlerRemoveFeed)))
+	commandsMap.register("renamefeed", middlewareAudit(middlewareLoggedIn(handlerRenameFeed)))
+	commandsMap.register("setfeedurl", middlewareAudit(middlewareLoggedIn(handlerSetFeedURL)))
+	commandsMap.register("transferfeed", middlewareAudit(middlewareLoggedIn(handlerTransferFeed)))
+	commandsMap.register("follow", middlewareAudit(middlewareLoggedIn(handlerFollow)))
 	commandsMap.register("following", middlewareLoggedIn(handlerFollowing))
-	commandsMap.register("unfollow", middlewareLoggedIn(handlerUnfollow))
+	commandsMap.register("unfollow", middlewareAudit(middlewareLoggedIn(handlerUnfollow)))
+	commandsMap.register("editfollow", middlewareAudit(middlewareLoggedIn(handlerEditFollow)))
 	commandsMap.register("browse", middlewareLoggedIn(handlerBrowse))
+	commandsMap.register("read", middlewareAudit(middlewareLoggedIn(handlerRead)))
+	commandsMap.register("mark-read", middlewareAudit(middlewareLoggedIn(handlerMarkRead)))
+	commandsMap.register("star", middlewareAudit(middlewareLoggedIn(handlerStar)))
+	commandsMap.register("unstar", middlewareAudit(middlewareLoggedIn(handlerUnstar)))
+	commandsMap.register("starred", middlewareLoggedIn(handlerStarred))
+	commandsMap.register("search", middlewareLoggedIn(handlerSearch))
+	commandsMap.register("alert", middlewareAudit(middlewareLoggedIn(handlerAlert)))
+	commandsMap.register("alerts", middlewareLoggedIn(handlerAlerts))
+	commandsMap.register("rules", middlewareAudit(middlewareLoggedIn(handlerRules)))
+	commandsMap.register("folder", middlewareAudit(middlewareLoggedIn(handlerFolder)))
+	commandsMap.register("export", middlewareLoggedIn(handlerExport))
+	commandsMap.register("apikey", middlewareAudit(middlewareLoggedIn(handlerAPIKey)))
+	commandsMap.register("fever", middlewareAudit(middlewareLoggedIn(handlerFever)))
+	commandsMap.register("timeline", middlewareAudit(middlewareLoggedIn(handlerTimelineToken)))
+	commandsMap.register("webhook", middlewareAudit(middlewareLoggedIn(handlerWebhook)))
+	commandsMap.register("digest", middlewareAudit(middlewareLoggedIn(handlerDigest)))
+	commandsMap.register("serve", handlerServe)
 
 	if len(os.Args) < 2 {
 		fmt.Println("specify some command")
 
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	cmd := command { name: os.Args[1], arguments: os.Args[2:] }
@@ -74,62 +123,163 @@ func main() {
 	err = commandsMap.run(&mainState, cmd)
 	if err != nil {
 		fmt.Println(err)
-
-		os.Exit(1)
 	}
 
-	os.Exit(0)
+	os.Exit(exitCode(err))
 }
 
 func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
 	return func(s *state, cmd command) error {
-		user, err := s.db.GetUserByName(context.Background(), s.cfg.CurrentUserName)
+		user, err := getSessionUser(s)
 		if err != nil {
 			return err
 		}
 
-		handler(s, cmd, user)
+		return handler(s, cmd, user)
+	}
+}
 
+// stdin is shared by every prompt, separate readers would each buffer
+// ahead and lose lines of piped input meant for the next prompt
+var stdin = bufio.NewReader(os.Stdin)
+
+func confirm(question string) (bool, error) {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, err := stdin.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes", nil
+}
+
+// scrapeFeeds fetches the next feed, failing to fetch or save a feed is logged and
+// the feed is skipped, so that it doesn't hold up the other feeds, only failing to
+// pick or mark the feed stops agg
+func scrapeFeeds(ctx context.Context, s *state) error {
+	feedToFetch, err := s.db.GetNextFeedToFetch(ctx, time.Now())
+	if err == sql.ErrNoRows {
+		// every feed is pushed by its WebSub hub and was polled recently
 		return nil
 	}
+	if err != nil {
+		slog.Error("error while getting next feed to fetch", "error", err)
+
+		return err
+	}
+
+	logger := slog.With("feed_id", feedToFetch.ID, "url", feedToFetch.Url)
+
+	err = scrapeFeed(ctx, s, feedToFetch, logger)
+	if ctx.Err() != nil {
+		// left unmarked, so the feed is fetched first after the restart
+		logger.Info("fetch aborted by shutdown")
+
+		return ctx.Err()
+	}
+
+	// marked after failures too, the next run moves on to the other feeds
+	markErr := s.db.MarkFeedFetched(ctx, feedToFetch.ID)
+	if markErr != nil {
+		logger.Error("error while marking feed as fetched", "error", markErr)
+
+		return markErr
+	}
+
+	if err != nil {
+		logger.Warn("skipping feed until its next turn")
+	}
+
+	return nil
 }
 
-func scrapeFeeds(s *state) error {
-	feedToFetch, err := s.db.GetNextFeedToFetch(context.Background())
+func scrapeFeed(ctx context.Context, s *state, feedToFetch database.Feed, logger *slog.Logger) error {
+	start := time.Now()
+
+	feed, err := rss.FetchFeed(ctx, feedClient, feedToFetch.Url)
 	if err != nil {
-		fmt.Println("some error while getting next feed to fetch")
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if errors.Is(err, rss.ErrInvalidFeed) {
+			feedParseErrors.Inc()
+		}
+
+		logger.Error("error while fetching feed", "duration", time.Since(start), "error", err)
 
 		return err
 	}
 
-	err = s.db.MarkFeedFetched(context.Background(), feedToFetch.ID)
+	if feed.Hub != "" {
+		err = setWebSubHub(ctx, s, feedToFetch, feed)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Info("fetched feed", "title", feed.Channel.Title, "items", len(feed.Channel.Item), "duration", time.Since(start))
+
+	return savePosts(ctx, s, feedToFetch.ID, feed.Channel.Item)
+}
+
+// savePosts stores new items of the feed and runs them through alerts, rules and webhooks,
+// it is shared by polling and by content pushed from WebSub hubs
+func savePosts(ctx context.Context, s *state, feedID uuid.UUID, items []rss.RSSItem) error {
+	logger := slog.With("feed_id", feedID)
+
+	// the whole batch is saved in one transaction, so posts interrupted by shutdown
+	// are rolled back together with their alerts, rules and webhooks
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
 	if err != nil {
-		fmt.Println("some error while marking feed as fetched")
+		logger.Error("error while starting transaction", "error", err)
 
 		return err
 	}
+	defer tx.Rollback()
+
+	// rules get the state too, so they are applied inside the transaction as well
+	txState := *s
+	txState.db = database.New(timedDB{ DBTX: tx })
+	s = &txState
 
-	feed, err := rss.FetchFeed(context.Background(), feedToFetch.Url)
+	storedRules, err := s.db.GetRulesForFeed(ctx, feedID)
 	if err != nil {
-		fmt.Println("some error while fetching feed")
+		logger.Error("error while retrieving rules for feed", "error", err)
 
 		return err
 	}
 
-	fmt.Printf("\nScrapping feed \"%s\"\n", feed.Channel.Title)
+	rules, err := compileRules(storedRules)
+	if err != nil {
+		return err
+	}
 
-	for i, item := range feed.Channel.Item {
-		fmt.Printf("\t%d. %s\n", i, item.Title)
+	inserted := 0
+	skipped := 0
 
+	for _, item := range items {
 		publishedAt, err := time.Parse(time.RFC1123Z, item.PubDate)
 		if err != nil {
-			fmt.Println("some error while parsing publishing time of the post")
-			fmt.Println(err)
+			feedParseErrors.Inc()
 
-			return err
+			// one broken item doesn't keep the rest of the feed from being saved
+			logger.Warn("skipping post with unparsable publishing time", "post_url", item.Link, "pub_date", item.PubDate, "error", err)
+
+			skipped++
+
+			continue
+		}
+
+		author := item.Author
+		if author == "" {
+			author = item.Creator
 		}
 
-		s.db.CreatePost(context.Background(), database.CreatePostParams{
+		post, err := s.db.CreatePost(ctx, database.CreatePostParams{
 			ID: uuid.New(),
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
@@ -137,58 +287,163 @@ func scrapeFeeds(s *state) error {
 			Url: item.Link,
 			Description: sql.NullString{ String: item.Description, Valid: true },
 			PublishedAt: publishedAt,
-			FeedID: feedToFetch.ID,
+			FeedID: feedID,
+			Author: sql.NullString{ String: author, Valid: author != "" },
+			Categories: sql.NullString{ String: strings.Join(item.Category, ", "), Valid: len(item.Category) != 0 },
 		})
-		// if err != nil {
-		// 	fmt.Println("some error while saving post")
-		// 	fmt.Println(err)
-		//
-		// 	return err
-		// }
+		if err == sql.ErrNoRows {
+			// post is already saved by one of the previous scrapes
+			continue
+		}
+		if err != nil {
+			logger.Error("error while saving post", "post_url", item.Link, "error", err)
+
+			return err
+		}
+
+		inserted++
+
+		logger.Debug("saved post", "post_id", post.ID, "post_url", post.Url, "title", item.Title)
+
+		// rules go first, so that posts hidden by them don't trigger alerts
+		_, err = applyRules(s, rules, post)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.db.RecordAlertHitsForPost(ctx, database.RecordAlertHitsForPostParams{
+			CreatedAt: time.Now(),
+			PostID: post.ID,
+		})
+		if err != nil {
+			logger.Error("error while matching post against alerts", "post_id", post.ID, "error", err)
+
+			return err
+		}
+
+		_, err = s.db.QueueWebhookDeliveriesForPost(ctx, database.QueueWebhookDeliveriesForPostParams{
+			CreatedAt: time.Now(),
+			PostID: post.ID,
+		})
+		if err != nil {
+			logger.Error("error while queueing webhook deliveries", "post_id", post.ID, "error", err)
+
+			return err
+		}
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("error while committing posts", "error", err)
+
+		return err
+	}
+
+	duplicates := len(items) - inserted - skipped
+
+	postsInserted.Add(float64(inserted))
+	postsDuplicate.Add(float64(duplicates))
+
+	logger.Info("saved posts", "items", len(items), "inserted", inserted, "duplicates", duplicates, "skipped", skipped)
+
 	return nil
 }
 
 func handlerRegister(s *state, cmd command) error {
 	if len(cmd.arguments) != 1 {
-		return fmt.Errorf("there should be one argument for register command - user name")
+		return usageError("there should be one argument for register command - user name")
 	}
 
-	err := s.cfg.SetUser(cmd.arguments[0])
+	password, err := readNewPassword()
 	if err != nil {
 		return err
 	}
 
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	// without any admin nobody could manage the database, so the first user becomes one
+	admins, err := s.db.CountAdmins(context.Background())
+	if err != nil {
+		slog.Error("error while counting admins", "error", err)
+
+		return err
+	}
+
+	role := roleMember
+	if admins == 0 {
+		role = roleAdmin
+	}
+
 	user, err := s.db.CreateUser(context.Background(), database.CreateUserParams{
 		ID: uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Name: cmd.arguments[0],
+		PasswordHash: passwordHash,
+		Role: role,
 	})
 	if err != nil {
 		return err
 	}
 
+	err = startSession(s, user)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println("successfull register")
-	fmt.Println(user)
+	fmt.Printf("%s [%s] %s\n", user.Name, user.ID, user.Role)
 
 	return nil
 }
 
 func handlerLogin(s *state, cmd command) error {
-	if len(cmd.arguments) != 1 {
-		return fmt.Errorf("there should be one argument for login command - user name")
+	flags := flag.NewFlagSet("login", flag.ContinueOnError)
+	reset := flags.Bool("reset", false, "set a new password with a reset token given by an admin")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
 	}
 
-	_, err := s.db.GetUserByName(context.Background(), cmd.arguments[0])
+	if flags.NArg() != 1 {
+		return usageError("there should be one argument for login command - user name")
+	}
+
+	user, err := s.db.GetUserByName(context.Background(), flags.Arg(0))
 	if err != nil {
-		fmt.Println("no such user")
+		slog.Error("no such user", "name", flags.Arg(0), "error", err)
 
 		return err
 	}
 
-	err = s.cfg.SetUser(cmd.arguments[0])
+	if !user.PasswordHash.Valid && !*reset {
+		// users registered before passwords were introduced can't just pick one,
+		// anybody knowing their name could do that
+		return notAllowedError("%s has no password yet, ask an admin for a reset token and login with --reset", user.Name)
+	}
+
+	if *reset {
+		err = resetPassword(s, user)
+		if err != nil {
+			return err
+		}
+	} else {
+		password, err := readPassword("Password: ")
+		if err != nil {
+			return err
+		}
+
+		err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte(password))
+		if err != nil {
+			return notAllowedError("wrong password")
+		}
+	}
+
+	err = startSession(s, user)
 	if err != nil {
 		return err
 	}
@@ -198,14 +453,91 @@ func handlerLogin(s *state, cmd command) error {
 	return nil
 }
 
-func handlerReset(s *state, cmd command) error {
-	if len(cmd.arguments) != 0 {
-		return fmt.Errorf("there shouldn't be any arguments for reset command")
+func handlerReset(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("reset", flag.ContinueOnError)
+	scope := flags.String("scope", "all", "what to reset - all, posts, user or fetch")
+	userName := flags.String("user", "", "user whose data is reset, required for user scope")
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+	dryRun := flags.Bool("dry-run", false, "only report what would be reset")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return usageError("reset command accepts only --scope, --user, --yes and --dry-run flags")
+	}
+
+	if (*scope == "user") != (*userName != "") {
+		return usageError("--user should be given with user scope and only with it")
+	}
+
+	stats, err := s.db.GetDatabaseStats(context.Background())
+	if err != nil {
+		slog.Error("error while counting what would be reset", "error", err)
+
+		return err
+	}
+
+	var user database.User
+
+	switch *scope {
+	case "all":
+		fmt.Printf("reset will remove %d users, %d feeds, %d follows and %d posts\n", stats.UsersCount, stats.FeedsCount, stats.FollowsCount, stats.PostsCount)
+	case "posts":
+		fmt.Printf("reset will remove %d posts, %d starred posts will be kept\n", stats.PostsCount - stats.StarredPostsCount, stats.StarredPostsCount)
+	case "fetch":
+		fmt.Printf("reset will mark %d feeds as never fetched\n", stats.FetchedFeedsCount)
+	case "user":
+		user, err = s.db.GetUserByName(context.Background(), *userName)
+		if err != nil {
+			slog.Error("no such user", "name", *userName, "error", err)
+
+			return err
+		}
+
+		userStats, err := s.db.GetUserDataStats(context.Background(), user.ID)
+		if err != nil {
+			slog.Error("error while counting what would be reset", "error", err)
+
+			return err
+		}
+
+		fmt.Printf("reset will remove %d follows, %d read marks, %d starred posts, %d alerts, %d rules, %d folders, %d sessions, %d api keys, %d fever credentials, %d webhooks with %d deliveries, %d digest subscriptions and %d timeline tokens of %s\n", userStats.FollowsCount, userStats.ReadsCount, userStats.SavedPostsCount, userStats.AlertsCount, userStats.RulesCount, userStats.FoldersCount, userStats.SessionsCount, userStats.ApiKeysCount, userStats.FeverCredentialsCount, userStats.WebhooksCount, userStats.WebhookDeliveriesCount, userStats.DigestSubscriptionsCount, userStats.TimelineTokensCount, user.Name)
+	default:
+		return usageError("--scope should be one of all, posts, user or fetch")
+	}
+
+	if *dryRun {
+		return nil
+	}
+
+	if !*yes {
+		confirmed, err := confirm("proceed with reset?")
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Println("nothing is reset")
+
+			return nil
+		}
 	}
 
-	err := s.db.Reset(context.Background())
+	switch *scope {
+	case "all":
+		err = s.db.Reset(context.Background())
+	case "posts":
+		_, err = s.db.ResetPosts(context.Background())
+	case "fetch":
+		_, err = s.db.ResetFetchState(context.Background())
+	case "user":
+		err = s.db.ResetUserData(context.Background(), user.ID)
+	}
 	if err != nil {
-		fmt.Println("reset failed")
+		slog.Error("reset failed", "scope", *scope, "error", err)
 
 		return err
 	}
@@ -217,20 +549,27 @@ func handlerReset(s *state, cmd command) error {
 
 func handlerUsers(s *state, cmd command) error {
 	if len(cmd.arguments) != 0 {
-		return fmt.Errorf("there shouldn't be any arguments for users command")
+		return usageError("there shouldn't be any arguments for users command")
 	}
 
 	users, err := s.db.GetUsers(context.Background())
 	if err != nil {
-		fmt.Println("some error while retrieving users")
+		slog.Error("error while retrieving users", "error", err)
 
 		return err
 	}
 
+	// not being logged in is fine for listing users
+	currentUser, _ := getSessionUser(s)
+
 	for _, user := range users {
-		msg := fmt.Sprintf("* %s", user)
+		msg := fmt.Sprintf("* %s", user.Name)
 
-		if user == s.cfg.CurrentUserName {
+		if user.Role == roleAdmin {
+			msg = fmt.Sprintf("%s (admin)", msg)
+		}
+
+		if user.Name == currentUser.Name {
 			msg = fmt.Sprintf("%s (current)", msg)
 		}
 
@@ -241,32 +580,120 @@ func handlerUsers(s *state, cmd command) error {
 }
 
 func handlerAggregate(s *state, cmd command, currentUser database.User) error {
-	if len(cmd.arguments) != 1 {
-		return fmt.Errorf("there should be one argument for agg command - time between requests")
+	flags := flag.NewFlagSet("agg", flag.ContinueOnError)
+	metricsAddr := flags.String("metrics-addr", "", "address to serve prometheus metrics on, e.g. localhost:9100")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageError("there should be one argument for agg command - time between requests, optionally preceded by --metrics-addr flag")
 	}
 
-	timeBetweenRequests, err := time.ParseDuration(cmd.arguments[0])
+	timeBetweenRequests, err := time.ParseDuration(flags.Arg(0))
 	if err != nil {
-		fmt.Println("error while parsing argument as time duration")
+		slog.Error("error while parsing argument as time duration", "error", err)
 
-		return err
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+
+	if *metricsAddr != "" {
+		err = serveMetrics(s, *metricsAddr)
+		if err != nil {
+			slog.Error("error while starting metrics server", "error", err)
+
+			return err
+		}
+
+		slog.Info("serving metrics", "addr", *metricsAddr)
 	}
 
-	fmt.Printf("Collecting feeds every %s\n\n", timeBetweenRequests.String())
+	// SIGINT or SIGTERM cancel the context, the fetch in flight is aborted and the
+	// batch being saved is rolled back, SIGHUP reloads the config between runs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	slog.Info("collecting feeds", "every", timeBetweenRequests.String())
 
 	ticker := time.NewTicker(timeBetweenRequests)
-	for ; ; <-ticker.C {
-		err = scrapeFeeds(s)
+	defer ticker.Stop()
+
+	for {
+		err = aggregate(ctx, s)
+		if ctx.Err() != nil {
+			slog.Info("shutting down")
 
+			return nil
+		}
 		if err != nil {
 			return err
 		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("shutting down")
+
+				return nil
+			case <-reload:
+				reloadConfig(s)
+			case <-ticker.C:
+				break wait
+			}
+		}
 	}
 }
 
+// aggregate is one run of agg, fetching the next feed and sending what is due
+func aggregate(ctx context.Context, s *state) error {
+	err := scrapeFeeds(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	err = deliverWebhooks(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	return sendDueDigests(ctx, s)
+}
+
+// reloadConfig rereads the config on SIGHUP, the old one is kept if the new one is broken
+func reloadConfig(s *state) {
+	cfg, err := config.Read()
+	if err != nil {
+		slog.Error("error while reloading config, keeping the old one", "error", err)
+
+		return
+	}
+
+	err = setupLogger(cfg)
+	if err != nil {
+		slog.Error("error while reloading config, keeping the old one", "error", err)
+
+		return
+	}
+
+	if cfg.DbUrl != s.cfg.DbUrl {
+		slog.Warn("db_url change takes effect only after restart")
+	}
+
+	s.cfg = cfg
+
+	slog.Info("reloaded config")
+}
+
 func handlerAddFeed(s *state, cmd command, currentUser database.User) error {
 	if len(cmd.arguments) != 2 {
-		return fmt.Errorf("there should be two arguments for addfeed command - feed name and feed url")
+		return usageError("there should be two arguments for addfeed command - feed name and feed url")
 	}
 
 	feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
@@ -289,7 +716,7 @@ func handlerAddFeed(s *state, cmd command, currentUser database.User) error {
 		FeedID: feed.ID,
 	})
 	if err != nil {
-		fmt.Println("some error while following the feed")
+		slog.Error("error while following the feed", "error", err)
 
 		return err
 	}
@@ -302,12 +729,12 @@ func handlerAddFeed(s *state, cmd command, currentUser database.User) error {
 
 func handlerFeeds(s *state, cmd command) error {
 	if len(cmd.arguments) != 0 {
-		return fmt.Errorf("there shouldn't be any arguments for feeds command")
+		return usageError("there shouldn't be any arguments for feeds command")
 	}
 
 	feeds, err := s.db.GetFeeds(context.Background())
 	if err != nil {
-		fmt.Println("some error while retrieving feeds")
+		slog.Error("error while retrieving feeds", "error", err)
 
 		return err
 	}
@@ -317,7 +744,7 @@ func handlerFeeds(s *state, cmd command) error {
 
 		user, err := s.db.GetUserById(context.Background(), feed.UserID)
 		if err != nil {
-			fmt.Println("some error while retrieving user who has added feed")
+			slog.Error("error while retrieving user who has added feed", "error", err)
 
 			return err
 		}
@@ -330,12 +757,12 @@ func handlerFeeds(s *state, cmd command) error {
 
 func handlerFollow(s *state, cmd command, currentUser database.User) error {
 	if len(cmd.arguments) != 1 {
-		return fmt.Errorf("there should be one argument for follow command - url of feed to follow")
+		return usageError("there should be one argument for follow command - url of feed to follow")
 	}
 
 	feed, err := s.db.GetFeedByURL(context.Background(), cmd.arguments[0])
 	if err != nil {
-		fmt.Println("some error while retrieving feed to follow")
+		slog.Error("error while retrieving feed to follow", "error", err)
 
 		return err
 	}
@@ -348,7 +775,7 @@ func handlerFollow(s *state, cmd command, currentUser database.User) error {
 		FeedID: feed.ID,
 	})
 	if err != nil {
-		fmt.Println("some error while following the feed")
+		slog.Error("error while following the feed", "error", err)
 
 		return err
 	}
@@ -360,31 +787,82 @@ func handlerFollow(s *state, cmd command, currentUser database.User) error {
 
 func handlerFollowing(s *state, cmd command, currentUser database.User) error {
 	if len(cmd.arguments) != 0 {
-		return fmt.Errorf("there shouldn't be any arguments for following command")
+		return usageError("there shouldn't be any arguments for following command")
 	}
 
 	user_feed_follows, err := s.db.GetFeedFollowsForUser(context.Background(), currentUser.ID)
 	if err != nil {
-		fmt.Println("some error while retrieving feeds followed by current user")
+		slog.Error("error while retrieving feeds followed by current user", "error", err)
 
 		return err
 	}
 
+	unread_counts, err := s.db.GetUnreadCountsForUser(context.Background(), currentUser.ID)
+	if err != nil {
+		slog.Error("error while retrieving unread counts for current user", "error", err)
+
+		return err
+	}
+
+	unread_by_feed := make(map[uuid.UUID]int64)
+	for _, count := range unread_counts {
+		unread_by_feed[count.FeedID] = count.UnreadCount
+	}
+
+	unread_by_folder := make(map[string]int64)
+	for _, follows := range user_feed_follows {
+		if follows.FolderName.Valid {
+			unread_by_folder[follows.FolderName.String] += unread_by_feed[follows.FeedID]
+		}
+	}
+
+	// follows are ordered by folder with feeds outside of folders first
+	current_folder := ""
 	for _, follows := range user_feed_follows {
-		fmt.Printf("%s\n", follows.FeedName)
+		if !follows.FolderName.Valid {
+			fmt.Printf("%s (%d unread)%s\n", follows.DisplayName, unread_by_feed[follows.FeedID], followMarks(follows))
+
+			continue
+		}
+
+		if follows.FolderName.String != current_folder {
+			current_folder = follows.FolderName.String
+
+			fmt.Printf("%s/ (%d unread)\n", current_folder, unread_by_folder[current_folder])
+		}
+
+		fmt.Printf("\t%s (%d unread)%s\n", follows.DisplayName, unread_by_feed[follows.FeedID], followMarks(follows))
 	}
 
 	return nil
 }
 
+func followMarks(follows database.GetFeedFollowsForUserRow) string {
+	marks := ""
+
+	if follows.Priority != 0 {
+		marks = fmt.Sprintf("%s [priority %d]", marks, follows.Priority)
+	}
+
+	if !follows.Notify {
+		marks = fmt.Sprintf("%s [muted]", marks)
+	}
+
+	if follows.Hidden {
+		marks = fmt.Sprintf("%s [hidden]", marks)
+	}
+
+	return marks
+}
+
 func handlerUnfollow(s *state, cmd command, currentUser database.User) error {
 	if len(cmd.arguments) != 1 {
-		return fmt.Errorf("there should be one argument for unfollow command - url of feed to unfollow")
+		return usageError("there should be one argument for unfollow command - url of feed to unfollow")
 	}
 
 	feed, err := s.db.GetFeedByURL(context.Background(), cmd.arguments[0])
 	if err != nil {
-		fmt.Println("some error while retrieving feed to unfollow")
+		slog.Error("error while retrieving feed to unfollow", "error", err)
 
 		return err
 	}
@@ -394,7 +872,7 @@ func handlerUnfollow(s *state, cmd command, currentUser database.User) error {
 		FeedID: feed.ID,
 	})
 	if err != nil {
-		fmt.Println("some error while removing feed follow")
+		slog.Error("error while removing feed follow", "error", err)
 
 		return err
 	}
@@ -404,37 +882,167 @@ func handlerUnfollow(s *state, cmd command, currentUser database.User) error {
 	return nil
 }
 
-func handlerBrowse(s *state, cmd command, currentUser database.User) error {
-	if len(cmd.arguments) != 1 {
-		return fmt.Errorf("there should be one argument for browse command - limit of posts")
+func handlerEditFollow(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("editfollow", flag.ContinueOnError)
+	name := flags.String("name", "", "custom name of the feed, empty to use the feed's own name")
+	priority := flags.Int("priority", 0, "priority of the feed, higher is listed first")
+	notify := flags.Bool("notify", true, "whether alerts are raised for posts of the feed")
+	hidden := flags.Bool("hidden", false, "whether posts of the feed are hidden from browse")
+
+	if len(cmd.arguments) < 1 {
+		return usageError("editfollow command expects feed url followed by --name, --priority, --notify or --hidden")
 	}
 
-	limit, err := strconv.ParseInt(cmd.arguments[0], 10, 32)
+	err := parseFlags(flags, cmd.arguments[1:])
 	if err != nil {
-		limit = 2
+		return err
+	}
+
+	if flags.NArg() != 0 || flags.NFlag() == 0 {
+		return usageError("editfollow command expects feed url followed by --name, --priority, --notify or --hidden")
 	}
-	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
+
+	feed, err := s.db.GetFeedByURL(context.Background(), cmd.arguments[0])
+	if err != nil {
+		slog.Error("error while retrieving feed to edit", "error", err)
+
+		return err
+	}
+
+	follow, err := s.db.GetFeedFollow(context.Background(), database.GetFeedFollowParams{
+		UserID: currentUser.ID,
+		FeedID: feed.ID,
+	})
+	if err == sql.ErrNoRows {
+		return notFoundError("you don't follow this feed")
+	}
+	if err != nil {
+		slog.Error("error while retrieving follow to edit", "error", err)
+
+		return err
+	}
+
+	params := database.UpdateFeedFollowSettingsParams{
+		CustomName: follow.CustomName,
+		Priority: follow.Priority,
+		Notify: follow.Notify,
+		Hidden: follow.Hidden,
+		UpdatedAt: time.Now(),
 		UserID: currentUser.ID,
-		Limit: int32(limit),
+		FeedID: feed.ID,
+	}
+
+	// only flags given on the command line change the settings
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "name":
+			params.CustomName = sql.NullString{ String: *name, Valid: *name != "" }
+		case "priority":
+			params.Priority = int32(*priority)
+		case "notify":
+			params.Notify = *notify
+		case "hidden":
+			params.Hidden = *hidden
+		}
 	})
+
+	_, err = s.db.UpdateFeedFollowSettings(context.Background(), params)
 	if err != nil {
-		fmt.Println("some error while retrieving users posts")
+		slog.Error("error while saving follow settings", "error", err)
+
+		return err
+	}
+
+	fmt.Println("successfull follow edit")
+
+	return nil
+}
+
+func handlerBrowse(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("browse", flag.ContinueOnError)
+	limit := flags.Int("limit", 2, "maximum number of posts to show")
+	offset := flags.Int("offset", 0, "number of posts to skip")
+	feedURL := flags.String("feed", "", "show only posts of the feed with this url")
+	since := flags.String("since", "", "show only posts published on or after this date (YYYY-MM-DD)")
+	until := flags.String("until", "", "show only posts published before this date (YYYY-MM-DD)")
+	sortOrder := flags.String("sort", "newest", "sort order of posts - newest or oldest")
+	unread := flags.Bool("unread", false, "show only posts not read yet")
+	tag := flags.String("tag", "", "show only posts with this tag")
+	folder := flags.String("folder", "", "show only posts of feeds in this folder")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return usageError("browse command accepts only flags, see browse --help")
+	}
+
+	if *limit <= 0 || *offset < 0 {
+		return usageError("--limit should be positive and --offset shouldn't be negative")
+	}
+
+	if *sortOrder != "newest" && *sortOrder != "oldest" {
+		return usageError("--sort should be either newest or oldest")
+	}
+
+	params := database.GetPostsForUserParams{
+		UserID: currentUser.ID,
+		FeedUrl: sql.NullString{ String: *feedURL, Valid: *feedURL != "" },
+		UnreadOnly: *unread,
+		Tag: sql.NullString{ String: *tag, Valid: *tag != "" },
+		Folder: sql.NullString{ String: *folder, Valid: *folder != "" },
+		OldestFirst: *sortOrder == "oldest",
+		PostLimit: int32(*limit),
+		PostOffset: int32(*offset),
+	}
+
+	if *since != "" {
+		sinceDate, err := time.Parse(time.DateOnly, *since)
+		if err != nil {
+			slog.Error("error while parsing --since as date", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		params.Since = sql.NullTime{ Time: sinceDate, Valid: true }
+	}
+
+	if *until != "" {
+		untilDate, err := time.Parse(time.DateOnly, *until)
+		if err != nil {
+			slog.Error("error while parsing --until as date", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		params.Until = sql.NullTime{ Time: untilDate, Valid: true }
+	}
+
+	posts, err := s.db.GetPostsForUser(context.Background(), params)
+	if err != nil {
+		slog.Error("error while retrieving users posts", "error", err)
 
 		return err
 	}
 
 	for _, post := range posts {
-		fmt.Printf("Post \"%s\":\n", post.Title.String)
+		fmt.Printf("Post \"%s\" [%s] from %s:\n", post.Title.String, post.ID, post.FeedName)
 		fmt.Printf("\t%s\n", post.Description.String)
 	}
 
+	if len(posts) == *limit {
+		fmt.Printf("\nmore posts available, continue with --offset %d\n", *offset + *limit)
+	}
+
 	return nil
 }
 
 func (c *commands) run(s *state, cmd command) error {
 	handler, exst := c.commands[cmd.name]
 	if !exst {
-		return fmt.Errorf("no such command")
+		return usageError("no such command")
 	}
 
 	err := handler(s, cmd)
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"internal/config"
+	"internal/database"
+	"internal/rss"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testFeed(url string) []driver.Value {
+	now := time.Now()
+
+	return []driver.Value{ uuid.New().String(), now, now, "Example", url, uuid.New().String(), nil, int64(1) }
+}
+
+func TestScrapeFeedsSkipsBrokenFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	s, db := newFakeState(t, map[string]fakeResult{
+		"GetNextFeedToFetch": { rows: [][]driver.Value{ testFeed(server.URL) } },
+		"MarkFeedFetched": { rowsAffected: 1 },
+	})
+
+	err := scrapeFeeds(context.Background(), s)
+	if err != nil {
+		t.Errorf("scrapeFeeds() error = %v, want broken feed skipped", err)
+	}
+
+	if calls := len(db.called("MarkFeedFetched")); calls != 1 {
+		t.Errorf("MarkFeedFetched called %d times, want broken feed marked once", calls)
+	}
+}
+
+func TestScrapeFeedsShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the feed responds only after agg was asked to shut down
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	s, db := newFakeState(t, map[string]fakeResult{
+		"GetNextFeedToFetch": { rows: [][]driver.Value{ testFeed(server.URL) } },
+		"MarkFeedFetched": { rowsAffected: 1 },
+	})
+
+	err := scrapeFeeds(ctx, s)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("scrapeFeeds() error = %v, want %v", err, context.Canceled)
+	}
+
+	if calls := len(db.called("MarkFeedFetched")); calls != 0 {
+		t.Errorf("MarkFeedFetched called %d times, want aborted feed left for the next start", calls)
+	}
+}
+
+func TestScrapeFeedsDatabaseError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	failure := errors.New("connection reset")
+
+	s, _ := newFakeState(t, map[string]fakeResult{
+		"GetNextFeedToFetch": { rows: [][]driver.Value{ testFeed(server.URL) } },
+		"MarkFeedFetched": { err: failure },
+	})
+
+	err := scrapeFeeds(context.Background(), s)
+	if err == nil || err.Error() != failure.Error() {
+		t.Errorf("scrapeFeeds() error = %v, want %v", err, failure)
+	}
+}
+
+func TestAggregateContinuesAfterBrokenFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not a feed"))
+	}))
+	defer server.Close()
+
+	s, db := newFakeState(t, map[string]fakeResult{
+		"GetNextFeedToFetch": { rows: [][]driver.Value{ testFeed(server.URL) } },
+		"MarkFeedFetched": { rowsAffected: 1 },
+		"GetDueWebhookDeliveries": {},
+	})
+	s.cfg = &config.Config{}
+
+	err := aggregate(context.Background(), s)
+	if err != nil {
+		t.Errorf("aggregate() error = %v, want broken feed skipped", err)
+	}
+
+	if calls := len(db.called("GetDueWebhookDeliveries")); calls != 1 {
+		t.Errorf("GetDueWebhookDeliveries called %d times, want webhooks delivered after broken feed", calls)
+	}
+}
+
+func testPostRow(feedID uuid.UUID) []driver.Value {
+	now := time.Now()
+
+	return []driver.Value{ uuid.New().String(), now, now, "Hello", "https://example.com/hello", "", now, feedID.String(), nil, nil, nil, int64(1) }
+}
+
+func TestSavePostsSkipsUnparsablePubDate(t *testing.T) {
+	feedID := uuid.New()
+
+	s, db := newFakeState(t, map[string]fakeResult{
+		"GetRulesForFeed": {},
+		"CreatePost": { rows: [][]driver.Value{ testPostRow(feedID) } },
+		"RecordAlertHitsForPost": {},
+		"QueueWebhookDeliveriesForPost": {},
+	})
+
+	items := []rss.RSSItem{
+		{ Title: "First", Link: "https://example.com/1", PubDate: "Mon, 02 Jan 2006 15:04:05 -0700" },
+		{ Title: "Broken", Link: "https://example.com/2", PubDate: "yesterday" },
+		{ Title: "Third", Link: "https://example.com/3", PubDate: "Tue, 03 Jan 2006 15:04:05 -0700" },
+	}
+
+	err := savePosts(context.Background(), s, feedID, items)
+	if err != nil {
+		t.Fatalf("savePosts() error = %v", err)
+	}
+
+	if calls := len(db.called("CreatePost")); calls != 2 {
+		t.Errorf("CreatePost called %d times, want both valid posts saved", calls)
+	}
+
+	if len(db.called("COMMIT")) != 1 || len(db.called("ROLLBACK")) != 0 {
+		t.Errorf("transaction calls %v, want the batch committed", db.order())
+	}
+}
+
+func TestSavePostsAppliesRulesBeforeAlerts(t *testing.T) {
+	feedID := uuid.New()
+	now := time.Now()
+	rule := []driver.Value{ uuid.New().String(), now, now, uuid.New().String(), nil, "title", "substring", "hello", "hide", nil }
+
+	s, db := newFakeState(t, map[string]fakeResult{
+		"GetRulesForFeed": { rows: [][]driver.Value{ rule } },
+		"CreatePost": { rows: [][]driver.Value{ testPostRow(feedID) } },
+		"HidePost": { rowsAffected: 1 },
+		"RecordAlertHitsForPost": {},
+		"QueueWebhookDeliveriesForPost": {},
+	})
+
+	items := []rss.RSSItem{
+		{ Title: "Hello", Link: "https://example.com/hello", PubDate: "Mon, 02 Jan 2006 15:04:05 -0700" },
+	}
+
+	err := savePosts(context.Background(), s, feedID, items)
+	if err != nil {
+		t.Fatalf("savePosts() error = %v", err)
+	}
+
+	// posts hidden by rules shouldn't trigger alerts
+	hidden, alerted := -1, -1
+	for i, name := range db.order() {
+		switch name {
+		case "HidePost":
+			hidden = i
+		case "RecordAlertHitsForPost":
+			alerted = i
+		}
+	}
+
+	if hidden == -1 || alerted == -1 || hidden > alerted {
+		t.Errorf("calls %v, want the post hidden before alerts are recorded", db.order())
+	}
+}
+
+func TestEditFollowNotFollowed(t *testing.T) {
+	s, db := newFakeState(t, map[string]fakeResult{
+		"GetFeedByURL": { rows: [][]driver.Value{ testFeed("https://example.com/feed") } },
+		"GetFeedFollow": {},
+	})
+
+	err := handlerEditFollow(s, command{ name: "editfollow", arguments: []string{ "https://example.com/feed", "--priority", "5" } }, database.User{ ID: uuid.New() })
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("handlerEditFollow() error = %v, want not found", err)
+	}
+
+	if calls := len(db.called("UpdateFeedFollowSettings")); calls != 0 {
+		t.Errorf("UpdateFeedFollowSettings called %d times, want nothing changed", calls)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"internal/database"
+	"internal/metrics"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var metricsRegistry = metrics.NewRegistry()
+
+var (
+	feedFetches = metricsRegistry.NewCounter("gator_feed_fetches_total", "Feed fetches by response status, error for fetches without response.", "status")
+	feedFetchDuration = metricsRegistry.NewHistogram("gator_feed_fetch_duration_seconds", "Time taken by feed fetches including download of the body.", metrics.DefaultBuckets)
+	feedFetchBytes = metricsRegistry.NewCounter("gator_feed_fetch_bytes_total", "Bytes of fetched feed bodies.")
+	feedParseErrors = metricsRegistry.NewCounter("gator_feed_parse_errors_total", "Feeds and posts that couldn't be parsed.")
+	postsInserted = metricsRegistry.NewCounter("gator_posts_inserted_total", "New posts saved from polled and pushed feeds.")
+	postsDuplicate = metricsRegistry.NewCounter("gator_posts_duplicate_total", "Posts skipped as they were saved before.")
+	fetchQueueLag = metricsRegistry.NewGauge("gator_fetch_queue_lag_seconds", "Time since the feed waiting longest for fetch was fetched.")
+	dbQueryDuration = metricsRegistry.NewHistogram("gator_db_query_duration_seconds", "Time taken by database queries by query name.", metrics.DefaultBuckets, "query")
+)
+
+// feedClient fetches feeds, its transport records fetch metrics
+var feedClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: metricsTransport{ next: http.DefaultTransport },
+}
+
+type metricsTransport struct {
+	next http.RoundTripper
+}
+
+func (t metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		feedFetches.Inc("error")
+		feedFetchDuration.Observe(time.Since(start).Seconds())
+
+		return nil, err
+	}
+
+	feedFetches.Inc(strconv.Itoa(res.StatusCode))
+	res.Body = &metricsBody{ ReadCloser: res.Body, start: start }
+
+	return res, nil
+}
+
+// metricsBody counts bytes read from the body and observes the fetch duration once it is closed
+type metricsBody struct {
+	io.ReadCloser
+	start time.Time
+	bytes int
+}
+
+func (b *metricsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += n
+
+	return n, err
+}
+
+func (b *metricsBody) Close() error {
+	feedFetchBytes.Add(float64(b.bytes))
+	feedFetchDuration.Observe(time.Since(b.start).Seconds())
+
+	return b.ReadCloser.Close()
+}
+
+// timedDB records duration of every query, named by the comment sqlc puts in front of queries
+type timedDB struct {
+	database.DBTX
+}
+
+func (db timedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer observeQuery(query, time.Now())
+
+	return db.DBTX.ExecContext(ctx, query, args...)
+}
+
+func (db timedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer observeQuery(query, time.Now())
+
+	return db.DBTX.QueryContext(ctx, query, args...)
+}
+
+func (db timedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer observeQuery(query, time.Now())
+
+	return db.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+func observeQuery(query string, start time.Time) {
+	name := "unknown"
+
+	rest, found := strings.CutPrefix(query, "-- name: ")
+	if found {
+		name, _, _ = strings.Cut(rest, " ")
+	}
+
+	dbQueryDuration.Observe(time.Since(start).Seconds(), name)
+}
+
+// handlerMetrics serves metrics in the Prometheus text format, the queue lag is
+// computed on every scrape
+func handlerMetrics(s *state) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oldestFetch, err := s.db.GetOldestFeedFetch(r.Context())
+		if err != nil && err != sql.ErrNoRows {
+			slog.Error("error while computing fetch queue lag", "error", err)
+		}
+
+		if err == nil {
+			fetchQueueLag.Set(time.Since(oldestFetch).Seconds())
+		}
+
+		if err == sql.ErrNoRows {
+			fetchQueueLag.Set(0)
+		}
+
+		metricsRegistry.Handler().ServeHTTP(w, r)
+	}
+}
+
+// serveMetrics exposes /metrics of long running commands, listening is done
+// up front so that wrong address is reported before the command starts
+func serveMetrics(s *state, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", handlerMetrics(s))
+
+	server := &http.Server{
+		Handler: mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		err := server.Serve(listener)
+		if err != nil {
+			slog.Error("metrics server stopped", "addr", addr, "error", err)
+		}
+	}()
+
+	return nil
+}
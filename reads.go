@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func handlerRead(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 1 {
+		return usageError("there should be one argument for read command - id of the post")
+	}
+
+	postID, err := uuid.Parse(cmd.arguments[0])
+	if err != nil {
+		slog.Error("error while parsing argument as post id", "error", err)
+
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+
+	post, err := s.db.GetPostByIdForUser(context.Background(), database.GetPostByIdForUserParams{
+		ID: postID,
+		UserID: currentUser.ID,
+	})
+	if err == sql.ErrNoRows {
+		return notFoundError("no such post")
+	}
+	if err != nil {
+		slog.Error("error while retrieving post", "error", err)
+
+		return err
+	}
+
+	err = s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{
+		UserID: currentUser.ID,
+		PostID: post.ID,
+		ReadAt: time.Now(),
+	})
+	if err != nil {
+		slog.Error("error while marking post as read", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("Post \"%s\":\n", post.Title.String)
+	fmt.Printf("\t%s\n", post.Url)
+	fmt.Printf("\t%s\n", post.Description.String)
+
+	return nil
+}
+
+func handlerMarkRead(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("mark-read", flag.ContinueOnError)
+	feedURL := flags.String("feed", "", "mark posts of the feed with this url as read")
+	all := flags.Bool("all", false, "mark posts of all followed feeds as read")
+	before := flags.String("before", "", "mark posts published before this date (YYYY-MM-DD) as read")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 || (*feedURL == "" && !*all && *before == "") {
+		return usageError("mark-read command expects --feed <url>, --all or --before <date>")
+	}
+
+	if *all && *feedURL != "" {
+		return usageError("--all and --feed can't be used together")
+	}
+
+	params := database.MarkPostsReadForUserParams{
+		UserID: currentUser.ID,
+		ReadAt: time.Now(),
+	}
+
+	if *feedURL != "" {
+		feed, err := s.db.GetFeedByURL(context.Background(), *feedURL)
+		if err != nil {
+			slog.Error("error while retrieving feed to mark as read", "error", err)
+
+			return err
+		}
+
+		params.FeedID = uuid.NullUUID{ UUID: feed.ID, Valid: true }
+	}
+
+	if *before != "" {
+		beforeDate, err := time.Parse(time.DateOnly, *before)
+		if err != nil {
+			slog.Error("error while parsing --before as date", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		params.Before = sql.NullTime{ Time: beforeDate, Valid: true }
+	}
+
+	marked, err := s.db.MarkPostsReadForUser(context.Background(), params)
+	if err != nil {
+		slog.Error("error while marking posts as read", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("marked %d posts as read\n", marked)
+
+	return nil
+}
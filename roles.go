@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	roleAdmin = "admin"
+	roleMember = "member"
+)
+
+func middlewareAdmin(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
+	return middlewareLoggedIn(func(s *state, cmd command, user database.User) error {
+		if user.Role != roleAdmin {
+			return notAllowedError("%s command is available only to admins", cmd.name)
+		}
+
+		return handler(s, cmd, user)
+	})
+}
+
+func handlerGrant(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 1 {
+		return usageError("there should be one argument for grant command - user name")
+	}
+
+	user, err := s.db.GetUserByName(context.Background(), cmd.arguments[0])
+	if err != nil {
+		slog.Error("no such user", "name", cmd.arguments[0], "error", err)
+
+		return err
+	}
+
+	return changeRole(s, user, roleAdmin, currentUser)
+}
+
+func handlerRevoke(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 1 {
+		return usageError("there should be one argument for revoke command - user name")
+	}
+
+	user, err := s.db.GetUserByName(context.Background(), cmd.arguments[0])
+	if err != nil {
+		slog.Error("no such user", "name", cmd.arguments[0], "error", err)
+
+		return err
+	}
+
+	// only revoking an admin can leave the database without one
+	if user.Role == roleAdmin {
+		admins, err := s.db.CountAdmins(context.Background())
+		if err != nil {
+			slog.Error("error while counting admins", "error", err)
+
+			return err
+		}
+
+		if admins <= 1 {
+			return notAllowedError("the last admin can't be revoked")
+		}
+	}
+
+	return changeRole(s, user, roleMember, currentUser)
+}
+
+func changeRole(s *state, user database.User, role string, currentUser database.User) error {
+	if user.Role == role {
+		fmt.Printf("%s is already %s\n", user.Name, role)
+
+		return nil
+	}
+
+	err := s.db.SetUserRole(context.Background(), database.SetUserRoleParams{
+		Role: role,
+		UpdatedAt: time.Now(),
+		ID: user.ID,
+	})
+	if err != nil {
+		slog.Error("error while changing role", "error", err)
+
+		return err
+	}
+
+	_, err = s.db.CreateRoleChange(context.Background(), database.CreateRoleChangeParams{
+		ID: uuid.New(),
+		CreatedAt: time.Now(),
+		ChangedBy: uuid.NullUUID{ UUID: currentUser.ID, Valid: true },
+		UserID: user.ID,
+		Role: role,
+	})
+	if err != nil {
+		slog.Error("error while recording role change", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("%s is now %s\n", user.Name, role)
+
+	return nil
+}
+
+func handlerRoles(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("roles", flag.ContinueOnError)
+	limit := flags.Int("limit", 20, "maximum number of role changes to show")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 || *limit <= 0 {
+		return usageError("roles command accepts only positive --limit")
+	}
+
+	changes, err := s.db.GetRoleChanges(context.Background(), int32(*limit))
+	if err != nil {
+		slog.Error("error while retrieving role changes", "error", err)
+
+		return err
+	}
+
+	for _, change := range changes {
+		changedBy := "deleted user"
+		if change.ChangedByName.Valid {
+			changedBy = change.ChangedByName.String
+		}
+
+		fmt.Printf("[%s] %s made %s by %s\n", change.CreatedAt.Format(time.DateTime), change.UserName, change.Role, changedBy)
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func handlerRules(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) < 1 {
+		return usageError("rules command expects a subcommand - add, list, remove <rule-id> or apply")
+	}
+
+	switch cmd.arguments[0] {
+	case "add":
+		return addRule(s, cmd.arguments[1:], currentUser)
+	case "list":
+		rules, err := s.db.GetRulesForUser(context.Background(), currentUser.ID)
+		if err != nil {
+			slog.Error("error while retrieving rules", "error", err)
+
+			return err
+		}
+
+		for _, rule := range rules {
+			msg := fmt.Sprintf("* [%s] %s %s \"%s\" -> %s", rule.ID, rule.Field, rule.MatchType, rule.Pattern, rule.Action)
+
+			if rule.Tag.Valid {
+				msg = fmt.Sprintf("%s %s", msg, rule.Tag.String)
+			}
+
+			if !rule.FeedID.Valid {
+				msg = fmt.Sprintf("%s (all feeds)", msg)
+			}
+
+			fmt.Println(msg)
+		}
+	case "remove":
+		if len(cmd.arguments) != 2 {
+			return usageError("there should be one argument for rules remove command - id of the rule")
+		}
+
+		ruleID, err := uuid.Parse(cmd.arguments[1])
+		if err != nil {
+			slog.Error("error while parsing argument as rule id", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		removed, err := s.db.RemoveRule(context.Background(), database.RemoveRuleParams{
+			ID: ruleID,
+			UserID: currentUser.ID,
+		})
+		if err != nil {
+			slog.Error("error while removing rule", "error", err)
+
+			return err
+		}
+
+		if removed == 0 {
+			return notFoundError("no such rule")
+		}
+
+		fmt.Println("successfull rule remove")
+	case "apply":
+		if len(cmd.arguments) != 1 {
+			return usageError("there shouldn't be any arguments for rules apply command")
+		}
+
+		storedRules, err := s.db.GetRulesForUser(context.Background(), currentUser.ID)
+		if err != nil {
+			slog.Error("error while retrieving rules", "error", err)
+
+			return err
+		}
+
+		rules, err := compileRules(storedRules)
+		if err != nil {
+			return err
+		}
+
+		posts, err := s.db.GetAllPostsForUser(context.Background(), currentUser.ID)
+		if err != nil {
+			slog.Error("error while retrieving users posts", "error", err)
+
+			return err
+		}
+
+		applied := 0
+		for _, post := range posts {
+			count, err := applyRules(s, rules, post)
+			if err != nil {
+				return err
+			}
+
+			applied += count
+		}
+
+		fmt.Printf("applied rules %d times to %d posts\n", applied, len(posts))
+	default:
+		return usageError("no such rules subcommand - %s", cmd.arguments[0])
+	}
+
+	return nil
+}
+
+func addRule(s *state, arguments []string, currentUser database.User) error {
+	flags := flag.NewFlagSet("rules add", flag.ContinueOnError)
+	field := flags.String("field", "title", "field of the post to match - title, description, author or category")
+	matchType := flags.String("match", "substring", "how to match the pattern - substring ignoring case or case sensitive regex")
+	pattern := flags.String("pattern", "", "substring or regular expression to look for")
+	action := flags.String("action", "hide", "what to do with matching posts - hide, read, star or tag")
+	tag := flags.String("tag", "", "tag to put on matching posts, required for tag action")
+	feedURL := flags.String("feed", "", "apply rule only to the feed with this url")
+
+	err := parseFlags(flags, arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 || *pattern == "" {
+		return usageError("rules add command expects --pattern and optional --field, --match, --action, --tag and --feed")
+	}
+
+	switch *field {
+	case "title", "description", "author", "category":
+	default:
+		return usageError("--field should be one of title, description, author or category")
+	}
+
+	switch *matchType {
+	case "substring":
+	case "regex":
+		_, err := regexp.Compile(*pattern)
+		if err != nil {
+			slog.Error("error while compiling --pattern as regular expression", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+	default:
+		return usageError("--match should be either substring or regex")
+	}
+
+	switch *action {
+	case "hide", "read", "star":
+	case "tag":
+		if *tag == "" {
+			return usageError("--tag is required for tag action")
+		}
+	default:
+		return usageError("--action should be one of hide, read, star or tag")
+	}
+
+	params := database.CreateRuleParams{
+		ID: uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID: currentUser.ID,
+		Field: *field,
+		MatchType: *matchType,
+		Pattern: *pattern,
+		Action: *action,
+		Tag: sql.NullString{ String: *tag, Valid: *tag != "" },
+	}
+
+	if *feedURL != "" {
+		feed, err := s.db.GetFeedByURL(context.Background(), *feedURL)
+		if err != nil {
+			slog.Error("error while retrieving feed for the rule", "error", err)
+
+			return err
+		}
+
+		params.FeedID = uuid.NullUUID{ UUID: feed.ID, Valid: true }
+	}
+
+	rule, err := s.db.CreateRule(context.Background(), params)
+	if err != nil {
+		slog.Error("error while saving rule", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("successfully added rule [%s]\n", rule.ID)
+
+	return nil
+}
+
+// compiledRule keeps the regular expression of regex rules, so that it's compiled
+// once for all the posts the rules are applied to
+type compiledRule struct {
+	database.Rule
+	regex *regexp.Regexp
+}
+
+func compileRules(rules []database.Rule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+
+	for _, rule := range rules {
+		result := compiledRule{ Rule: rule }
+
+		if rule.MatchType == "regex" {
+			regex, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				slog.Error("error while compiling rule", "rule_id", rule.ID, "error", err)
+
+				return nil, err
+			}
+
+			result.regex = regex
+		}
+
+		compiled = append(compiled, result)
+	}
+
+	return compiled, nil
+}
+
+func applyRules(s *state, rules []compiledRule, post database.Post) (int, error) {
+	applied := 0
+
+	for _, rule := range rules {
+		if rule.FeedID.Valid && rule.FeedID.UUID != post.FeedID {
+			continue
+		}
+
+		if !ruleMatches(rule, post) {
+			continue
+		}
+
+		err := applyRule(s, rule.Rule, post)
+		if err != nil {
+			slog.Error("error while applying rule", "rule_id", rule.ID, "post_id", post.ID, "error", err)
+
+			return applied, err
+		}
+
+		applied++
+	}
+
+	return applied, nil
+}
+
+// ruleMatches looks for substrings ignoring case, while regular expressions are
+// case sensitive unless they start with (?i)
+func ruleMatches(rule compiledRule, post database.Post) bool {
+	var value string
+
+	switch rule.Field {
+	case "title":
+		value = post.Title.String
+	case "description":
+		value = post.Description.String
+	case "author":
+		value = post.Author.String
+	case "category":
+		value = post.Categories.String
+	}
+
+	if rule.regex != nil {
+		return rule.regex.MatchString(value)
+	}
+
+	return strings.Contains(strings.ToLower(value), strings.ToLower(rule.Pattern))
+}
+
+func applyRule(s *state, rule database.Rule, post database.Post) error {
+	switch rule.Action {
+	case "hide":
+		return s.db.HidePost(context.Background(), database.HidePostParams{
+			UserID: rule.UserID,
+			PostID: post.ID,
+			CreatedAt: time.Now(),
+		})
+	case "read":
+		return s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{
+			UserID: rule.UserID,
+			PostID: post.ID,
+			ReadAt: time.Now(),
+		})
+	case "star":
+		_, err := s.db.SavePost(context.Background(), database.SavePostParams{
+			UserID: rule.UserID,
+			PostID: post.ID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+
+		return err
+	case "tag":
+		return s.db.TagPost(context.Background(), database.TagPostParams{
+			UserID: rule.UserID,
+			PostID: post.ID,
+			Tag: rule.Tag.String,
+			CreatedAt: time.Now(),
+		})
+	}
+
+	return fmt.Errorf("unknown rule action - %s", rule.Action)
+}
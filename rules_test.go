@@ -0,0 +1,128 @@
+package main
+
+import (
+	"database/sql"
+	"internal/database"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func testPost() database.Post {
+	return database.Post{
+		ID: uuid.New(),
+		Title: sql.NullString{ String: "Go 1.26 Released", Valid: true },
+		Description: sql.NullString{ String: "The Go team is happy to announce", Valid: true },
+		Author: sql.NullString{ String: "Gopher", Valid: true },
+		Categories: sql.NullString{ String: "go, release", Valid: true },
+		FeedID: uuid.New(),
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	post := testPost()
+
+	tests := []struct {
+		name string
+		field string
+		matchType string
+		pattern string
+		want bool
+	}{
+		{ name: "title substring", field: "title", matchType: "substring", pattern: "released", want: true },
+		{ name: "substring ignores case", field: "title", matchType: "substring", pattern: "GO 1.26", want: true },
+		{ name: "title doesn't contain", field: "title", matchType: "substring", pattern: "rust", want: false },
+		{ name: "description", field: "description", matchType: "substring", pattern: "go team", want: true },
+		{ name: "author", field: "author", matchType: "substring", pattern: "gopher", want: true },
+		{ name: "category", field: "category", matchType: "substring", pattern: "release", want: true },
+		{ name: "other field isn't searched", field: "author", matchType: "substring", pattern: "released", want: false },
+		{ name: "substring isn't a regex", field: "title", matchType: "substring", pattern: "Go 1.2.", want: false },
+		{ name: "regex", field: "title", matchType: "regex", pattern: `^Go 1\.\d+`, want: true },
+		{ name: "regex doesn't match", field: "title", matchType: "regex", pattern: `^Rust`, want: false },
+		{ name: "regex is case sensitive", field: "title", matchType: "regex", pattern: `^go`, want: false },
+		{ name: "regex ignores case with flag", field: "title", matchType: "regex", pattern: `(?i)^go`, want: true },
+		{ name: "regex on category", field: "category", matchType: "regex", pattern: `\brelease\b`, want: true },
+		{ name: "regex matches inside of value", field: "description", matchType: "regex", pattern: `team is \w+`, want: true },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, err := compileRules([]database.Rule{ { Field: test.field, MatchType: test.matchType, Pattern: test.pattern } })
+			if err != nil {
+				t.Fatalf("compileRules() error = %v", err)
+			}
+
+			got := ruleMatches(rules[0], post)
+			if got != test.want {
+				t.Errorf("ruleMatches() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompileRulesInvalidRegex(t *testing.T) {
+	_, err := compileRules([]database.Rule{
+		{ Field: "title", MatchType: "substring", Pattern: "(" },
+		{ Field: "title", MatchType: "regex", Pattern: "(" },
+	})
+	if err == nil {
+		t.Errorf("compileRules() with invalid regex succeeded")
+	}
+}
+
+func TestRuleMatchesMissingField(t *testing.T) {
+	post := testPost()
+	post.Author = sql.NullString{}
+
+	rules, err := compileRules([]database.Rule{ { Field: "author", MatchType: "regex", Pattern: `.` } })
+	if err != nil {
+		t.Fatalf("compileRules() error = %v", err)
+	}
+
+	if ruleMatches(rules[0], post) {
+		t.Errorf("ruleMatches() on post without author matched")
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	post := testPost()
+	userID := uuid.New()
+
+	rules, err := compileRules([]database.Rule{
+		{ ID: uuid.New(), UserID: userID, Field: "title", MatchType: "substring", Pattern: "go", Action: "hide" },
+		{ ID: uuid.New(), UserID: userID, Field: "title", MatchType: "regex", Pattern: "^go", Action: "read" },
+		{ ID: uuid.New(), UserID: userID, FeedID: uuid.NullUUID{ UUID: uuid.New(), Valid: true }, Field: "title", MatchType: "substring", Pattern: "go", Action: "read" },
+		{ ID: uuid.New(), UserID: userID, FeedID: uuid.NullUUID{ UUID: post.FeedID, Valid: true }, Field: "category", MatchType: "regex", Pattern: "release$", Action: "tag", Tag: sql.NullString{ String: "releases", Valid: true } },
+	})
+	if err != nil {
+		t.Fatalf("compileRules() error = %v", err)
+	}
+
+	s, db := newFakeState(t, map[string]fakeResult{
+		"HidePost": { rowsAffected: 1 },
+		"MarkPostRead": { rowsAffected: 1 },
+		"TagPost": { rowsAffected: 1 },
+	})
+
+	applied, err := applyRules(s, rules, post)
+	if err != nil {
+		t.Fatalf("applyRules() error = %v", err)
+	}
+
+	if applied != 2 {
+		t.Errorf("applyRules() applied %d rules, want 2", applied)
+	}
+
+	if calls := len(db.called("MarkPostRead")); calls != 0 {
+		t.Errorf("MarkPostRead called %d times, want rules of other feeds and not matching rules skipped", calls)
+	}
+
+	tags := db.called("TagPost")
+	if len(tags) != 1 || tags[0][2] != "releases" {
+		t.Errorf("TagPost called with %v, want the post tagged releases", tags)
+	}
+
+	if calls := len(db.called("HidePost")); calls != 1 {
+		t.Errorf("HidePost called %d times, want once", calls)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func handlerStar(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("star", flag.ContinueOnError)
+	noteFlag := flags.String("note", "", "note of the starred post, empty to clear the note")
+
+	if len(cmd.arguments) < 1 {
+		return usageError("there should be at least one argument for star command - id of the post and optional note or --note flag")
+	}
+
+	err := parseFlags(flags, cmd.arguments[1:])
+	if err != nil {
+		return err
+	}
+
+	// without --note, starring again keeps the note the post was starred with
+	note := strings.Join(flags.Args(), " ")
+	noteSet := false
+
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "note" {
+			noteSet = true
+		}
+	})
+
+	if noteSet {
+		if flags.NArg() != 0 {
+			return usageError("star command accepts either note or --note flag")
+		}
+
+		note = *noteFlag
+	}
+
+	postID, err := uuid.Parse(cmd.arguments[0])
+	if err != nil {
+		slog.Error("error while parsing argument as post id", "error", err)
+
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+
+	post, err := s.db.GetPostByIdForUser(context.Background(), database.GetPostByIdForUserParams{
+		ID: postID,
+		UserID: currentUser.ID,
+	})
+	if err == sql.ErrNoRows {
+		return notFoundError("no such post")
+	}
+	if err != nil {
+		slog.Error("error while retrieving post to star", "error", err)
+
+		return err
+	}
+
+	_, err = s.db.SavePost(context.Background(), database.SavePostParams{
+		UserID: currentUser.ID,
+		PostID: post.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Note: sql.NullString{ String: note, Valid: note != "" },
+	})
+	if err != nil {
+		slog.Error("error while starring the post", "error", err)
+
+		return err
+	}
+
+	if noteSet && note == "" {
+		_, err = s.db.ClearSavedPostNote(context.Background(), database.ClearSavedPostNoteParams{
+			UpdatedAt: time.Now(),
+			UserID: currentUser.ID,
+			PostID: post.ID,
+		})
+		if err != nil {
+			slog.Error("error while clearing note of the starred post", "error", err)
+
+			return err
+		}
+	}
+
+	fmt.Printf("successfully starred post: %s\n", post.Title.String)
+
+	return nil
+}
+
+func handlerUnstar(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 1 {
+		return usageError("there should be one argument for unstar command - id of the post")
+	}
+
+	postID, err := uuid.Parse(cmd.arguments[0])
+	if err != nil {
+		slog.Error("error while parsing argument as post id", "error", err)
+
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+
+	removed, err := s.db.RemoveSavedPost(context.Background(), database.RemoveSavedPostParams{
+		UserID: currentUser.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		slog.Error("error while unstarring the post", "error", err)
+
+		return err
+	}
+
+	if removed == 0 {
+		return notFoundError("post %s is not starred", postID)
+	}
+
+	fmt.Println("successfull unstar")
+
+	return nil
+}
+
+func handlerStarred(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 0 {
+		return usageError("there shouldn't be any arguments for starred command")
+	}
+
+	posts, err := s.db.GetSavedPostsForUser(context.Background(), currentUser.ID)
+	if err != nil {
+		slog.Error("error while retrieving starred posts", "error", err)
+
+		return err
+	}
+
+	for _, post := range posts {
+		fmt.Printf("Post \"%s\" [%s]:\n", post.Title.String, post.ID)
+		fmt.Printf("\t%s\n", post.Url)
+
+		if post.Note.Valid {
+			fmt.Printf("\tNote: %s\n", post.Note.String)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"internal/database"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestStarNote(t *testing.T) {
+	postID := uuid.New()
+	post := testPostRow(uuid.New())
+	post[0] = postID.String()
+
+	tests := []struct {
+		name string
+		arguments []string
+		wantNote driver.Value
+		wantCleared bool
+		wantErr error
+	}{
+		{ name: "no note", arguments: []string{ postID.String() }, wantNote: nil },
+		{ name: "note as arguments", arguments: []string{ postID.String(), "read", "later" }, wantNote: "read later" },
+		{ name: "note flag", arguments: []string{ postID.String(), "--note", "read later" }, wantNote: "read later" },
+		{ name: "empty note flag clears the note", arguments: []string{ postID.String(), "--note", "" }, wantNote: nil, wantCleared: true },
+		{ name: "note flag and arguments", arguments: []string{ postID.String(), "--note", "read", "later" }, wantErr: errUsage },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, db := newFakeState(t, map[string]fakeResult{
+				"GetPostByIdForUser": { rows: [][]driver.Value{ post } },
+				"SavePost": { rows: [][]driver.Value{ { uuid.New().String(), postID.String(), time.Now(), time.Now(), nil } } },
+				"ClearSavedPostNote": { rowsAffected: 1 },
+			})
+
+			err := handlerStar(s, command{ name: "star", arguments: test.arguments }, database.User{ ID: uuid.New() })
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("handlerStar() error = %v, want %v", err, test.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("handlerStar() error = %v", err)
+			}
+
+			saves := db.called("SavePost")
+			if len(saves) != 1 || saves[0][4] != test.wantNote {
+				t.Errorf("SavePost called with %v, want note %v", saves, test.wantNote)
+			}
+
+			if cleared := len(db.called("ClearSavedPostNote")) == 1; cleared != test.wantCleared {
+				t.Errorf("note cleared %t, want %t", cleared, test.wantCleared)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+func handlerSearch(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := flags.Int("limit", 10, "maximum number of posts to show")
+	feedURL := flags.String("feed", "", "search only posts of the feed with this url")
+	since := flags.String("since", "", "search only posts published on or after this date (YYYY-MM-DD)")
+	until := flags.String("until", "", "search only posts published before this date (YYYY-MM-DD)")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return usageError("there should be at least one argument for search command - search query")
+	}
+
+	if *limit <= 0 {
+		return usageError("--limit should be positive")
+	}
+
+	params := database.SearchPostsForUserParams{
+		Query: strings.Join(flags.Args(), " "),
+		UserID: currentUser.ID,
+		FeedUrl: sql.NullString{ String: *feedURL, Valid: *feedURL != "" },
+		PostLimit: int32(*limit),
+	}
+
+	if *since != "" {
+		sinceDate, err := time.Parse(time.DateOnly, *since)
+		if err != nil {
+			slog.Error("error while parsing --since as date", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		params.Since = sql.NullTime{ Time: sinceDate, Valid: true }
+	}
+
+	if *until != "" {
+		untilDate, err := time.Parse(time.DateOnly, *until)
+		if err != nil {
+			slog.Error("error while parsing --until as date", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		params.Until = sql.NullTime{ Time: untilDate, Valid: true }
+	}
+
+	posts, err := s.db.SearchPostsForUser(context.Background(), params)
+	if err != nil {
+		slog.Error("error while searching posts", "error", err)
+
+		return err
+	}
+
+	if len(posts) == 0 {
+		fmt.Println("nothing found")
+
+		return nil
+	}
+
+	for _, post := range posts {
+		fmt.Printf("Post \"%s\" [%s] from %s, %s:\n", post.Title.String, post.ID, post.FeedName, post.PublishedAt.Format(time.DateOnly))
+		fmt.Printf("\t%s\n", post.Url)
+		fmt.Printf("\t%s\n", post.Snippet)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type userHandler func(s *state, w http.ResponseWriter, r *http.Request, user database.User) error
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func handlerServe(s *state, cmd command) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	publicURL := flags.String("public-url", "", "url the server is reachable at from the internet, enables WebSub subscriptions")
+	metricsAddr := flags.String("metrics-addr", "", "address to serve prometheus metrics on, e.g. localhost:9100")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return usageError("serve command accepts only --addr, --public-url and --metrics-addr flags")
+	}
+
+	// metrics are kept off the public address as they aren't authenticated
+	if *metricsAddr != "" {
+		err = serveMetrics(s, *metricsAddr)
+		if err != nil {
+			slog.Error("error while starting metrics server", "error", err)
+
+			return err
+		}
+
+		slog.Info("serving metrics", "addr", *metricsAddr)
+	}
+
+	if *publicURL != "" {
+		parsed, err := url.Parse(*publicURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return usageError("--public-url should be an absolute http or https url")
+		}
+
+		// hubs call back to the server, so subscriptions are only made in server mode
+		go runWebSub(s, strings.TrimSuffix(*publicURL, "/"))
+	}
+
+	server := &http.Server{
+		Addr: *addr,
+		Handler: newServeMux(s),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	slog.Info("serving api and web ui", "addr", *addr)
+
+	return server.ListenAndServe()
+}
+
+func newServeMux(s *state) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /v1/me", middlewareAPIKey(s, apiGetMe))
+	mux.HandleFunc("GET /v1/users", middlewareAPIKey(s, apiGetUsers))
+	mux.HandleFunc("GET /v1/feeds", middlewareAPIKey(s, apiGetFeeds))
+	mux.HandleFunc("POST /v1/feeds", middlewareAPIKey(s, middlewareAuditRequest(apiCreateFeed)))
+	mux.HandleFunc("GET /v1/follows", middlewareAPIKey(s, apiGetFollows))
+	mux.HandleFunc("POST /v1/follows", middlewareAPIKey(s, middlewareAuditRequest(apiCreateFollow)))
+	mux.HandleFunc("DELETE /v1/follows/{feedID}", middlewareAPIKey(s, middlewareAuditRequest(apiDeleteFollow)))
+	mux.HandleFunc("GET /v1/posts", middlewareAPIKey(s, apiGetPosts))
+	mux.HandleFunc("GET /v1/posts/{postID}", middlewareAPIKey(s, apiGetPost))
+	mux.HandleFunc("POST /v1/posts/{postID}/read", middlewareAPIKey(s, middlewareAuditRequest(apiReadPost)))
+	mux.HandleFunc("PUT /v1/posts/{postID}/star", middlewareAPIKey(s, middlewareAuditRequest(apiStarPost)))
+	mux.HandleFunc("DELETE /v1/posts/{postID}/star", middlewareAPIKey(s, middlewareAuditRequest(apiUnstarPost)))
+
+	mux.HandleFunc("/fever/", handlerFeverAPI(s))
+
+	mux.HandleFunc("GET /websub/{feedID}/{token}", handlerWebSubVerify(s))
+	mux.HandleFunc("POST /websub/{feedID}/{token}", handlerWebSubContent(s))
+
+	mux.Handle("GET /{$}", http.RedirectHandler("/web/", http.StatusFound))
+	mux.HandleFunc("GET /web/login", handlerWebLoginPage(s))
+	mux.HandleFunc("POST /web/login", handlerWebLogin(s))
+	mux.HandleFunc("POST /web/logout", middlewareWebSession(s, middlewareAuditRequest(webLogout)))
+	mux.HandleFunc("GET /web/{$}", middlewareWebSession(s, webRiver))
+	mux.HandleFunc("POST /web/posts/{postID}/read", middlewareWebSession(s, middlewareAuditRequest(webReadPost)))
+	mux.HandleFunc("POST /web/posts/{postID}/star", middlewareWebSession(s, middlewareAuditRequest(webStarPost)))
+	mux.HandleFunc("POST /web/posts/{postID}/unstar", middlewareWebSession(s, middlewareAuditRequest(webUnstarPost)))
+	mux.HandleFunc("GET /web/subscriptions", middlewareWebSession(s, webSubscriptions))
+	mux.HandleFunc("POST /web/subscriptions/follow", middlewareWebSession(s, middlewareAuditRequest(webFollow)))
+	mux.HandleFunc("POST /web/subscriptions/add", middlewareWebSession(s, middlewareAuditRequest(webAddFeed)))
+	mux.HandleFunc("POST /web/subscriptions/unfollow", middlewareWebSession(s, middlewareAuditRequest(webUnfollow)))
+
+	// timelines are authenticated by token in the url so that any feed reader can subscribe to them
+	mux.HandleFunc("GET /users/{name}/feed.atom", handlerTimeline(s, timelineAtom))
+	mux.HandleFunc("GET /users/{name}/feed.rss", handlerTimeline(s, timelineRSS))
+	mux.HandleFunc("GET /users/{name}/folders/{folder}/feed.atom", handlerTimeline(s, timelineAtom))
+	mux.HandleFunc("GET /users/{name}/folders/{folder}/feed.rss", handlerTimeline(s, timelineRSS))
+
+	return mux
+}
+
+// middlewareAPIKey authenticates requests by "Authorization: Bearer <key>" header
+// and turns errors returned by handler into json error responses
+func middlewareAPIKey(s *state, handler userHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || key == "" {
+			respondWithJSON(w, http.StatusUnauthorized, apiError{ Error: "missing api key" })
+
+			return
+		}
+
+		user, err := s.db.GetUserByAPIKey(r.Context(), database.GetUserByAPIKeyParams{
+			KeyHash: hashToken(key),
+			LastUsedAt: sql.NullTime{ Time: time.Now(), Valid: true },
+		})
+		if err == sql.ErrNoRows {
+			respondWithJSON(w, http.StatusUnauthorized, apiError{ Error: "invalid api key" })
+
+			return
+		}
+		if err != nil {
+			respondWithError(w, r, err)
+
+			return
+		}
+
+		err = handler(s, w, r, user)
+		if err != nil {
+			respondWithError(w, r, err)
+		}
+	}
+}
+
+func respondWithError(w http.ResponseWriter, r *http.Request, err error) {
+	status := httpStatus(err)
+	if status == http.StatusInternalServerError {
+		// internal details are only logged, not sent to clients
+		slog.Error("error while handling request", "method", r.Method, "path", r.URL.Path, "error", err)
+
+		respondWithJSON(w, status, apiError{ Error: "internal error" })
+
+		return
+	}
+
+	respondWithJSON(w, status, apiError{ Error: err.Error() })
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(payload)
+	if err != nil {
+		slog.Error("error while encoding response", "error", err)
+	}
+}
+
+func decodeJSON(r *http.Request, v any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+
+	return nil
+}
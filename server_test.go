@@ -0,0 +1,145 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"internal/database"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMiddlewareAPIKey(t *testing.T) {
+	userID := uuid.New()
+	user := []driver.Value{ userID.String(), time.Now(), time.Now(), "alice", nil, "member" }
+
+	tests := []struct {
+		name string
+		authorization string
+		lookup fakeResult
+		handlerErr error
+		wantStatus int
+		wantError string
+		wantHandler bool
+	}{
+		{ name: "missing header", wantStatus: http.StatusUnauthorized, wantError: "missing api key" },
+		{ name: "other scheme", authorization: "Basic YWxpY2U6c2VjcmV0", wantStatus: http.StatusUnauthorized, wantError: "missing api key" },
+		{ name: "empty key", authorization: "Bearer ", wantStatus: http.StatusUnauthorized, wantError: "missing api key" },
+		{ name: "unknown key", authorization: "Bearer unknown", lookup: fakeResult{}, wantStatus: http.StatusUnauthorized, wantError: "invalid api key" },
+		{ name: "lookup failure", authorization: "Bearer key", lookup: fakeResult{ err: errors.New("connection refused") }, wantStatus: http.StatusInternalServerError, wantError: "internal error" },
+		{ name: "valid key", authorization: "Bearer key", lookup: fakeResult{ rows: [][]driver.Value{ user } }, wantStatus: http.StatusNoContent, wantHandler: true },
+		{ name: "handler error", authorization: "Bearer key", lookup: fakeResult{ rows: [][]driver.Value{ user } }, handlerErr: notFoundError("no such post"), wantStatus: http.StatusNotFound, wantError: "not found: no such post", wantHandler: true },
+		// internal details are only logged
+		{ name: "handler internal error", authorization: "Bearer key", lookup: fakeResult{ rows: [][]driver.Value{ user } }, handlerErr: errors.New("pq: connection reset"), wantStatus: http.StatusInternalServerError, wantError: "internal error", wantHandler: true },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, db := newFakeState(t, map[string]fakeResult{
+				"GetUserByAPIKey": test.lookup,
+			})
+
+			handlerCalled := false
+
+			handler := middlewareAPIKey(s, func(s *state, w http.ResponseWriter, r *http.Request, currentUser database.User) error {
+				handlerCalled = true
+
+				if currentUser.ID != userID || currentUser.Name != "alice" {
+					t.Errorf("handler got user %+v, want alice", currentUser)
+				}
+
+				if test.handlerErr != nil {
+					return test.handlerErr
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler(recorder, req)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+
+			if handlerCalled != test.wantHandler {
+				t.Errorf("handler called %t, want %t", handlerCalled, test.wantHandler)
+			}
+
+			if test.wantError != "" {
+				var response apiError
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				if err != nil || response.Error != test.wantError {
+					t.Errorf("response = %s, want error %q", recorder.Body.String(), test.wantError)
+				}
+			}
+
+			// keys are looked up only by their hash
+			for _, args := range db.called("GetUserByAPIKey") {
+				if args[0] != hashToken("key") && args[0] != hashToken("unknown") {
+					t.Errorf("GetUserByAPIKey called with %v, want hash of the key", args[0])
+				}
+			}
+		})
+	}
+}
+
+func TestAPIPostOutsideFollowedFeeds(t *testing.T) {
+	tests := []struct {
+		name string
+		handler userHandler
+	}{
+		{ name: "get", handler: apiGetPost },
+		{ name: "read", handler: apiReadPost },
+		{ name: "star", handler: apiStarPost },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the post isn't in any feed the user follows
+			s, db := newFakeState(t, map[string]fakeResult{
+				"GetPostByIdForUser": {},
+			})
+
+			user := database.User{ ID: uuid.New() }
+			postID := uuid.New()
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/posts/" + postID.String(), nil)
+			req.SetPathValue("postID", postID.String())
+
+			err := test.handler(s, httptest.NewRecorder(), req, user)
+			if !errors.Is(err, errNotFound) {
+				t.Errorf("handler error = %v, want not found", err)
+			}
+
+			calls := db.called("GetPostByIdForUser")
+			if len(calls) != 1 || calls[0][0] != postID.String() || calls[0][1] != user.ID.String() {
+				t.Errorf("GetPostByIdForUser called with %v, want the post of the user", calls)
+			}
+		})
+	}
+}
+
+func TestMetricsNotPublic(t *testing.T) {
+	s, _ := newFakeState(t, map[string]fakeResult{})
+
+	// metrics are only served on --metrics-addr
+	recorder := httptest.NewRecorder()
+	newServeMux(s).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,61 @@
+-- name: CreateAlert :one
+INSERT INTO alerts (id, created_at, updated_at, user_id, query)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: GetAlertsForUser :many
+SELECT * FROM alerts
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: RemoveAlert :execrows
+DELETE FROM alerts
+WHERE id = $1 AND user_id = $2;
+
+-- name: RecordAlertHitsForPost :execrows
+INSERT INTO alert_hits (alert_id, post_id, created_at)
+SELECT alerts.id, posts.id, @created_at::timestamp
+FROM alerts
+INNER JOIN posts
+ON posts.id = @post_id::uuid
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = alerts.user_id
+WHERE feed_follows.notify
+AND posts.search_vector @@ websearch_to_tsquery('english', alerts.query)
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.post_id = posts.id AND hidden_posts.user_id = alerts.user_id
+)
+ON CONFLICT (alert_id, post_id) DO NOTHING;
+
+-- name: GetAlertHitsForUser :many
+SELECT
+    alert_hits.created_at,
+    alerts.query,
+    posts.id AS post_id,
+    posts.title,
+    posts.url,
+    COALESCE(feed_follows.custom_name, feeds.name) AS feed_name
+FROM alert_hits
+INNER JOIN alerts
+ON alert_hits.alert_id = alerts.id
+INNER JOIN posts
+ON alert_hits.post_id = posts.id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = alerts.user_id
+WHERE alerts.user_id = $1
+AND NOT feed_follows.hidden
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.post_id = posts.id AND hidden_posts.user_id = alerts.user_id
+)
+ORDER BY alert_hits.created_at DESC
+LIMIT $2;
@@ -0,0 +1,21 @@
+-- name: CreateAuditLogEntry :exec
+INSERT INTO audit_log (id, created_at, user_id, user_name, command, arguments, outcome, error)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+);
+
+-- name: GetAuditLog :many
+SELECT * FROM audit_log
+WHERE (sqlc.narg(user_name)::text IS NULL OR user_name = sqlc.narg(user_name)::text)
+AND (sqlc.narg(command)::text IS NULL OR command = sqlc.narg(command)::text)
+AND (sqlc.narg(since)::timestamp IS NULL OR created_at >= sqlc.narg(since)::timestamp)
+AND (sqlc.narg(until)::timestamp IS NULL OR created_at < sqlc.narg(until)::timestamp)
+ORDER BY created_at DESC
+LIMIT @entry_limit;
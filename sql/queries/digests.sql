@@ -0,0 +1,66 @@
+-- name: SetDigestSubscription :one
+INSERT INTO digest_subscriptions (user_id, created_at, updated_at, email, frequency)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (user_id) DO UPDATE
+SET email = EXCLUDED.email, frequency = EXCLUDED.frequency, updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: GetDigestSubscription :one
+SELECT * FROM digest_subscriptions
+WHERE user_id = $1;
+
+-- name: RemoveDigestSubscription :execrows
+DELETE FROM digest_subscriptions
+WHERE user_id = $1;
+
+-- name: GetDueDigestSubscriptions :many
+SELECT
+    digest_subscriptions.*,
+    users.name AS user_name
+FROM digest_subscriptions
+INNER JOIN users
+ON digest_subscriptions.user_id = users.id
+WHERE digest_subscriptions.last_sent_at IS NULL
+OR (digest_subscriptions.frequency = 'daily' AND digest_subscriptions.last_sent_at <= @now::timestamp - INTERVAL '1 day')
+OR (digest_subscriptions.frequency = 'weekly' AND digest_subscriptions.last_sent_at <= @now::timestamp - INTERVAL '7 days');
+
+-- name: MarkDigestSent :exec
+UPDATE digest_subscriptions
+SET last_sent_at = $1
+WHERE user_id = $2;
+
+-- name: GetDigestPostsForUser :many
+SELECT
+    folders.name AS folder_name,
+    COALESCE(feed_follows.custom_name, feeds.name) AS feed_name,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    COUNT(*) OVER () AS total_count
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN folders
+ON feed_follows.folder_id = folders.id
+WHERE feed_follows.user_id = @user_id
+AND NOT feed_follows.hidden
+AND posts.created_at >= @since::timestamp
+AND NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = @user_id
+)
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.post_id = posts.id AND hidden_posts.user_id = @user_id
+)
+ORDER BY folders.name NULLS FIRST, feed_name, posts.published_at DESC
+LIMIT 200;
@@ -24,15 +24,30 @@ ON inserted_feed_follow.user_id = users.id;
 SELECT
     feed_follows.*,
     feeds.name AS feed_name,
-    users.name AS user_name
+    COALESCE(feed_follows.custom_name, feeds.name) AS display_name,
+    feeds.url AS feed_url,
+    users.name AS user_name,
+    folders.name AS folder_name
 FROM feed_follows
 INNER JOIN feeds
 ON feed_follows.feed_id = feeds.id
 INNER JOIN users
 ON feed_follows.user_id = users.id
-WHERE feed_follows.user_id = $1;
+LEFT JOIN folders
+ON feed_follows.folder_id = folders.id
+WHERE feed_follows.user_id = $1
+ORDER BY folders.name NULLS FIRST, feed_follows.priority DESC, COALESCE(feed_follows.custom_name, feeds.name);
 
 -- name: RemoveFeedFollowsForUser :exec
 DELETE FROM feed_follows
 WHERE feed_id = $1 AND user_id = $2;
 
+
+-- name: GetFeedFollow :one
+SELECT * FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: UpdateFeedFollowSettings :execrows
+UPDATE feed_follows
+SET custom_name = $1, priority = $2, notify = $3, hidden = $4, updated_at = $5
+WHERE user_id = $6 AND feed_id = $7;
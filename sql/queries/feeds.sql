@@ -23,6 +23,63 @@ SET last_fetched_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1;
 
 -- name: GetNextFeedToFetch :one
+SELECT feeds.* FROM feeds
+LEFT JOIN websub_subscriptions
+ON feeds.id = websub_subscriptions.feed_id AND websub_subscriptions.state = 'active'
+WHERE websub_subscriptions.lease_expires_at IS NULL
+OR websub_subscriptions.lease_expires_at <= @now::timestamp
+OR feeds.last_fetched_at IS NULL
+OR feeds.last_fetched_at <= @now::timestamp - INTERVAL '1 day'
+ORDER BY feeds.last_fetched_at NULLS FIRST
+LIMIT 1;
+
+-- name: GetFeedStats :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.feed_id = $1) AS follows_count,
+    (SELECT COUNT(*) FROM posts WHERE posts.feed_id = $1) AS posts_count,
+    (
+        SELECT COUNT(*) FROM user_saved_posts
+        INNER JOIN posts
+        ON user_saved_posts.post_id = posts.id
+        WHERE posts.feed_id = $1
+    ) AS starred_count,
+    (
+        SELECT COUNT(*) FROM user_saved_posts
+        INNER JOIN posts
+        ON user_saved_posts.post_id = posts.id
+        WHERE posts.feed_id = $1 AND user_saved_posts.note IS NOT NULL
+    ) AS noted_count,
+    (
+        SELECT COUNT(*) FROM post_tags
+        INNER JOIN posts
+        ON post_tags.post_id = posts.id
+        WHERE posts.feed_id = $1
+    ) AS tags_count;
+
+-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1;
+
+-- name: RenameFeed :exec
+UPDATE feeds
+SET name = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: SetFeedURL :exec
+UPDATE feeds
+SET url = $1, updated_at = $2, last_fetched_at = NULL
+WHERE id = $3;
+
+-- name: TransferFeed :exec
+UPDATE feeds
+SET user_id = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: GetFeedByShortID :one
 SELECT * FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
+WHERE short_id = $1;
+
+-- name: GetOldestFeedFetch :one
+SELECT COALESCE(last_fetched_at, created_at) AS fetched_at FROM feeds
+ORDER BY last_fetched_at NULLS FIRST, created_at
 LIMIT 1;
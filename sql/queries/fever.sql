@@ -0,0 +1,104 @@
+-- name: SetFeverAPIKey :exec
+INSERT INTO fever_credentials (user_id, api_key, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id) DO UPDATE
+SET api_key = EXCLUDED.api_key, created_at = EXCLUDED.created_at;
+
+-- name: RemoveFeverAPIKey :execrows
+DELETE FROM fever_credentials
+WHERE user_id = $1;
+
+-- name: GetUserByFeverAPIKey :one
+SELECT users.* FROM users
+INNER JOIN fever_credentials
+ON fever_credentials.user_id = users.id
+WHERE fever_credentials.api_key = $1;
+
+-- name: GetFeverFeedsForUser :many
+SELECT
+    feeds.short_id,
+    COALESCE(feed_follows.custom_name, feeds.name) AS title,
+    feeds.url,
+    feeds.last_fetched_at,
+    folders.short_id AS folder_short_id
+FROM feed_follows
+INNER JOIN feeds
+ON feed_follows.feed_id = feeds.id
+LEFT JOIN folders
+ON feed_follows.folder_id = folders.id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.short_id;
+
+-- name: GetFeverItemsForUser :many
+SELECT
+    posts.short_id,
+    feeds.short_id AS feed_short_id,
+    posts.title,
+    posts.author,
+    posts.description,
+    posts.url,
+    posts.published_at,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = @user_id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_saved_posts
+        WHERE user_saved_posts.post_id = posts.id AND user_saved_posts.user_id = @user_id
+    ) AS is_saved
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE feed_follows.user_id = @user_id
+AND NOT feed_follows.hidden
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.post_id = posts.id AND hidden_posts.user_id = @user_id
+)
+AND (sqlc.narg(since_id)::bigint IS NULL OR posts.short_id > sqlc.narg(since_id)::bigint)
+AND (sqlc.narg(max_id)::bigint IS NULL OR posts.short_id < sqlc.narg(max_id)::bigint)
+AND (sqlc.narg(with_ids)::text IS NULL OR posts.short_id = ANY(string_to_array(sqlc.narg(with_ids)::text, ',')::bigint[]))
+ORDER BY
+    CASE WHEN sqlc.narg(max_id)::bigint IS NULL THEN posts.short_id END,
+    posts.short_id DESC
+LIMIT 50;
+
+-- name: CountFeverItemsForUser :one
+SELECT COUNT(*) FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+AND NOT feed_follows.hidden
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.post_id = posts.id AND hidden_posts.user_id = $1
+);
+
+-- name: GetFeverUnreadItemIDs :many
+SELECT posts.short_id FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+AND NOT feed_follows.hidden
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.post_id = posts.id AND hidden_posts.user_id = $1
+)
+AND NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = $1
+)
+ORDER BY posts.short_id;
+
+-- name: GetFeverSavedItemIDs :many
+SELECT posts.short_id FROM user_saved_posts
+INNER JOIN posts
+ON user_saved_posts.post_id = posts.id
+WHERE user_saved_posts.user_id = $1
+ORDER BY posts.short_id;
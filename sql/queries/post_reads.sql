@@ -0,0 +1,35 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkPostsReadForUser :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT @user_id::uuid, posts.id, @read_at::timestamp
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = @user_id::uuid
+AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id)::uuid)
+AND (sqlc.narg(before)::timestamp IS NULL OR posts.published_at < sqlc.narg(before)::timestamp)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: GetUnreadCountsForUser :many
+SELECT
+    posts.feed_id,
+    COUNT(*) AS unread_count
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+LEFT JOIN post_reads
+ON post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1 AND post_reads.post_id IS NULL
+GROUP BY posts.feed_id;
+
+-- name: MarkPostUnread :exec
+DELETE FROM post_reads
+WHERE user_id = $1 AND post_id = $2;
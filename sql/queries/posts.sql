@@ -1,5 +1,5 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories)
 VALUES (
     $1,
     $2,
@@ -8,15 +8,97 @@ VALUES (
     $5,
     $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10
 )
+ON CONFLICT (url) DO NOTHING
 RETURNING *;
 
 -- name: GetPostsForUser :many
-SELECT * FROM posts
-WHERE feed_id IN (
-    SELECT id FROM feeds
-    WHERE user_id = $1
+SELECT
+    posts.*,
+    COALESCE(feed_follows.custom_name, feeds.name) AS feed_name,
+    feeds.url AS feed_url,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = @user_id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_saved_posts
+        WHERE user_saved_posts.post_id = posts.id AND user_saved_posts.user_id = @user_id
+    ) AS is_saved
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE feed_follows.user_id = @user_id
+AND (sqlc.narg(feed_url)::text IS NULL OR feeds.url = sqlc.narg(feed_url)::text)
+AND (NOT feed_follows.hidden OR sqlc.narg(feed_url)::text IS NOT NULL)
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since)::timestamp)
+AND (sqlc.narg(until)::timestamp IS NULL OR posts.published_at < sqlc.narg(until)::timestamp)
+AND (NOT @unread_only::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = @user_id
+))
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.post_id = posts.id AND hidden_posts.user_id = @user_id
 )
-ORDER BY published_at DESC
-LIMIT $2;
+AND (sqlc.narg(tag)::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id AND post_tags.user_id = @user_id AND post_tags.tag = sqlc.narg(tag)::text
+))
+AND (sqlc.narg(folder)::text IS NULL OR feed_follows.folder_id IN (
+    SELECT id FROM folders
+    WHERE folders.user_id = @user_id AND folders.name = sqlc.narg(folder)::text
+))
+ORDER BY
+    CASE WHEN @oldest_first::boolean THEN posts.published_at END ASC,
+    CASE WHEN NOT @oldest_first::boolean THEN posts.published_at END DESC,
+    posts.id
+LIMIT @post_limit
+OFFSET @post_offset;
+
+-- name: GetPostByIdForUser :one
+SELECT posts.* FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE posts.id = $1 AND feed_follows.user_id = $2;
+
+-- name: SearchPostsForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    COALESCE(feed_follows.custom_name, feeds.name) AS feed_name,
+    ts_rank(posts.search_vector, websearch_to_tsquery('english', @query::text)) AS rank,
+    ts_headline('english', coalesce(posts.description, ''), websearch_to_tsquery('english', @query::text), 'StartSel=**, StopSel=**, MaxFragments=2') AS snippet
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE feed_follows.user_id = @user_id
+AND posts.search_vector @@ websearch_to_tsquery('english', @query::text)
+AND (sqlc.narg(feed_url)::text IS NULL OR feeds.url = sqlc.narg(feed_url)::text)
+AND (NOT feed_follows.hidden OR sqlc.narg(feed_url)::text IS NOT NULL)
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since)::timestamp)
+AND (sqlc.narg(until)::timestamp IS NULL OR posts.published_at < sqlc.narg(until)::timestamp)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT @post_limit;
+
+-- name: GetAllPostsForUser :many
+SELECT posts.* FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY posts.published_at DESC;
+
+-- name: GetPostByShortIDForUser :one
+SELECT posts.* FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE posts.short_id = $1 AND feed_follows.user_id = $2;
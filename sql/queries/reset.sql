@@ -0,0 +1,71 @@
+-- name: GetDatabaseStats :one
+SELECT
+    (SELECT COUNT(*) FROM users) AS users_count,
+    (SELECT COUNT(*) FROM feeds) AS feeds_count,
+    (SELECT COUNT(*) FROM feeds WHERE last_fetched_at IS NOT NULL) AS fetched_feeds_count,
+    (SELECT COUNT(*) FROM feed_follows) AS follows_count,
+    (SELECT COUNT(*) FROM posts) AS posts_count,
+    (SELECT COUNT(DISTINCT post_id) FROM user_saved_posts) AS starred_posts_count;
+
+-- name: GetUserDataStats :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = $1) AS follows_count,
+    (SELECT COUNT(*) FROM post_reads WHERE post_reads.user_id = $1) AS reads_count,
+    (SELECT COUNT(*) FROM user_saved_posts WHERE user_saved_posts.user_id = $1) AS saved_posts_count,
+    (SELECT COUNT(*) FROM alerts WHERE alerts.user_id = $1) AS alerts_count,
+    (SELECT COUNT(*) FROM rules WHERE rules.user_id = $1) AS rules_count,
+    (SELECT COUNT(*) FROM folders WHERE folders.user_id = $1) AS folders_count,
+    (SELECT COUNT(*) FROM sessions WHERE sessions.user_id = $1) AS sessions_count,
+    (SELECT COUNT(*) FROM api_keys WHERE api_keys.user_id = $1) AS api_keys_count,
+    (SELECT COUNT(*) FROM fever_credentials WHERE fever_credentials.user_id = $1) AS fever_credentials_count,
+    (SELECT COUNT(*) FROM webhooks WHERE webhooks.user_id = $1) AS webhooks_count,
+    (
+        SELECT COUNT(*) FROM webhook_deliveries
+        INNER JOIN webhooks
+        ON webhook_deliveries.webhook_id = webhooks.id
+        WHERE webhooks.user_id = $1
+    ) AS webhook_deliveries_count,
+    (SELECT COUNT(*) FROM digest_subscriptions WHERE digest_subscriptions.user_id = $1) AS digest_subscriptions_count,
+    (SELECT COUNT(*) FROM timeline_tokens WHERE timeline_tokens.user_id = $1) AS timeline_tokens_count;
+
+-- name: ResetPosts :execrows
+DELETE FROM posts
+WHERE id NOT IN (
+    SELECT post_id FROM user_saved_posts
+);
+
+-- name: ResetFetchState :execrows
+UPDATE feeds
+SET last_fetched_at = NULL
+WHERE last_fetched_at IS NOT NULL;
+
+-- name: ResetUserData :exec
+WITH deleted_follows AS (
+    DELETE FROM feed_follows WHERE feed_follows.user_id = $1
+), deleted_reads AS (
+    DELETE FROM post_reads WHERE post_reads.user_id = $1
+), deleted_saved_posts AS (
+    DELETE FROM user_saved_posts WHERE user_saved_posts.user_id = $1
+), deleted_hidden_posts AS (
+    DELETE FROM hidden_posts WHERE hidden_posts.user_id = $1
+), deleted_post_tags AS (
+    DELETE FROM post_tags WHERE post_tags.user_id = $1
+), deleted_alerts AS (
+    DELETE FROM alerts WHERE alerts.user_id = $1
+), deleted_rules AS (
+    DELETE FROM rules WHERE rules.user_id = $1
+), deleted_sessions AS (
+    DELETE FROM sessions WHERE sessions.user_id = $1
+), deleted_api_keys AS (
+    DELETE FROM api_keys WHERE api_keys.user_id = $1
+), deleted_fever_credentials AS (
+    DELETE FROM fever_credentials WHERE fever_credentials.user_id = $1
+), deleted_webhooks AS (
+    DELETE FROM webhooks WHERE webhooks.user_id = $1
+), deleted_digest_subscriptions AS (
+    DELETE FROM digest_subscriptions WHERE digest_subscriptions.user_id = $1
+), deleted_timeline_tokens AS (
+    DELETE FROM timeline_tokens WHERE timeline_tokens.user_id = $1
+)
+DELETE FROM folders
+WHERE folders.user_id = $1;
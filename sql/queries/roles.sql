@@ -0,0 +1,37 @@
+-- name: SetUserRole :exec
+UPDATE users
+SET role = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: CountAdmins :one
+SELECT COUNT(*) FROM users
+WHERE role = 'admin';
+
+-- name: CountAdminsWithPassword :one
+SELECT COUNT(*) FROM users
+WHERE role = 'admin' AND password_hash IS NOT NULL;
+
+-- name: CreateRoleChange :one
+INSERT INTO role_changes (id, created_at, changed_by, user_id, role)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: GetRoleChanges :many
+SELECT
+    role_changes.created_at,
+    role_changes.role,
+    users.name AS user_name,
+    changers.name AS changed_by_name
+FROM role_changes
+INNER JOIN users
+ON role_changes.user_id = users.id
+LEFT JOIN users AS changers
+ON role_changes.changed_by = changers.id
+ORDER BY role_changes.created_at DESC
+LIMIT $1;
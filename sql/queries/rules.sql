@@ -0,0 +1,51 @@
+-- name: CreateRule :one
+INSERT INTO rules (id, created_at, updated_at, user_id, feed_id, field, match_type, pattern, action, tag)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
+)
+RETURNING *;
+
+-- name: GetRulesForUser :many
+SELECT * FROM rules
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetRulesForFeed :many
+SELECT rules.* FROM rules
+INNER JOIN feed_follows
+ON feed_follows.user_id = rules.user_id
+WHERE feed_follows.feed_id = $1
+AND (rules.feed_id IS NULL OR rules.feed_id = feed_follows.feed_id)
+ORDER BY rules.created_at;
+
+-- name: RemoveRule :execrows
+DELETE FROM rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: HidePost :exec
+INSERT INTO hidden_posts (user_id, post_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: TagPost :exec
+INSERT INTO post_tags (user_id, post_id, tag, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id, post_id, tag) DO NOTHING;
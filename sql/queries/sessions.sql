@@ -0,0 +1,38 @@
+-- name: CreateSession :one
+INSERT INTO sessions (token_hash, created_at, expires_at, user_id)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
+
+-- name: GetUserBySession :one
+SELECT users.* FROM users
+INNER JOIN sessions
+ON sessions.user_id = users.id
+WHERE sessions.token_hash = $1 AND sessions.expires_at > $2;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE token_hash = $1;
+
+-- name: DeleteUserSessions :exec
+DELETE FROM sessions
+WHERE user_id = $1;
+
+-- name: SetPasswordReset :exec
+INSERT INTO password_resets (user_id, token_hash, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at;
+
+-- name: UsePasswordReset :execrows
+DELETE FROM password_resets
+WHERE user_id = $1 AND token_hash = $2 AND expires_at > $3;
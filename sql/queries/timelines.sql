@@ -0,0 +1,19 @@
+-- name: SetTimelineToken :exec
+INSERT INTO timeline_tokens (user_id, token_hash, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash, created_at = EXCLUDED.created_at;
+
+-- name: RemoveTimelineToken :execrows
+DELETE FROM timeline_tokens
+WHERE user_id = $1;
+
+-- name: GetUserByTimelineToken :one
+SELECT users.* FROM users
+INNER JOIN timeline_tokens
+ON timeline_tokens.user_id = users.id
+WHERE timeline_tokens.token_hash = $1;
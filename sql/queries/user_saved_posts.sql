@@ -0,0 +1,32 @@
+-- name: SavePost :one
+INSERT INTO user_saved_posts (user_id, post_id, created_at, updated_at, note)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET note = COALESCE(EXCLUDED.note, user_saved_posts.note), updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: ClearSavedPostNote :execrows
+UPDATE user_saved_posts
+SET note = NULL, updated_at = $1
+WHERE user_id = $2 AND post_id = $3;
+
+-- name: RemoveSavedPost :execrows
+DELETE FROM user_saved_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetSavedPostsForUser :many
+SELECT
+    posts.*,
+    user_saved_posts.note,
+    user_saved_posts.created_at AS saved_at
+FROM user_saved_posts
+INNER JOIN posts
+ON user_saved_posts.post_id = posts.id
+WHERE user_saved_posts.user_id = $1
+ORDER BY user_saved_posts.created_at DESC;
@@ -1,10 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, password_hash, role)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    $6
 )
 RETURNING *;
 
@@ -20,4 +22,29 @@ WHERE id = $1;
 DELETE FROM users;
 
 -- name: GetUsers :many
-SELECT name FROM users;
+SELECT name, role FROM users;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
+
+-- name: RenameUser :exec
+UPDATE users
+SET name = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: GetFeedsCountForUser :one
+SELECT COUNT(*) FROM feeds
+WHERE user_id = $1;
+
+-- name: GetFeedsFollowedByOthersCountForUser :one
+SELECT COUNT(*) FROM feeds
+WHERE feeds.user_id = $1 AND EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id <> $1
+);
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $1, updated_at = $2
+WHERE id = $3;
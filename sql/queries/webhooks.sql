@@ -0,0 +1,93 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, created_at, updated_at, user_id, url, secret, feed_id, folder_id, payload_template)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+RETURNING *;
+
+-- name: GetWebhooksForUser :many
+SELECT
+    webhooks.*,
+    feeds.url AS feed_url,
+    folders.name AS folder_name
+FROM webhooks
+LEFT JOIN feeds
+ON webhooks.feed_id = feeds.id
+LEFT JOIN folders
+ON webhooks.folder_id = folders.id
+WHERE webhooks.user_id = $1
+ORDER BY webhooks.created_at;
+
+-- name: RemoveWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: QueueWebhookDeliveriesForPost :execrows
+INSERT INTO webhook_deliveries (id, created_at, updated_at, webhook_id, post_id, next_attempt_at)
+SELECT gen_random_uuid(), @created_at::timestamp, @created_at::timestamp, webhooks.id, posts.id, @created_at::timestamp
+FROM webhooks
+INNER JOIN posts
+ON posts.id = @post_id::uuid
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = webhooks.user_id
+WHERE (webhooks.feed_id IS NULL OR webhooks.feed_id = posts.feed_id)
+AND (webhooks.folder_id IS NULL OR webhooks.folder_id = feed_follows.folder_id)
+AND NOT feed_follows.hidden
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.post_id = posts.id AND hidden_posts.user_id = webhooks.user_id
+)
+ON CONFLICT (webhook_id, post_id) DO NOTHING;
+
+-- name: GetDueWebhookDeliveries :many
+SELECT
+    webhook_deliveries.id,
+    webhook_deliveries.attempts,
+    webhooks.id AS webhook_id,
+    webhooks.url AS webhook_url,
+    webhooks.secret,
+    webhooks.payload_template,
+    posts.id AS post_id,
+    posts.title,
+    posts.url AS post_url,
+    posts.description,
+    posts.author,
+    posts.published_at,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM webhook_deliveries
+INNER JOIN webhooks
+ON webhook_deliveries.webhook_id = webhooks.id
+INNER JOIN posts
+ON webhook_deliveries.post_id = posts.id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE webhook_deliveries.status = 'pending' AND webhook_deliveries.next_attempt_at <= $1
+ORDER BY webhook_deliveries.next_attempt_at
+LIMIT $2;
+
+-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = $1, attempts = $2, next_attempt_at = $3, response_code = $4, last_error = $5, updated_at = $6
+WHERE id = $7;
+
+-- name: GetWebhookDeliveries :many
+SELECT
+    webhook_deliveries.*,
+    posts.title
+FROM webhook_deliveries
+INNER JOIN webhooks
+ON webhook_deliveries.webhook_id = webhooks.id
+INNER JOIN posts
+ON webhook_deliveries.post_id = posts.id
+WHERE webhooks.id = $1 AND webhooks.user_id = $2
+ORDER BY webhook_deliveries.created_at DESC
+LIMIT $3;
@@ -0,0 +1,40 @@
+-- name: SetWebSubHub :exec
+INSERT INTO websub_subscriptions (feed_id, created_at, updated_at, hub_url, topic_url, secret, callback_token)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+ON CONFLICT (feed_id) DO UPDATE
+SET hub_url = EXCLUDED.hub_url, topic_url = EXCLUDED.topic_url, secret = EXCLUDED.secret, callback_token = EXCLUDED.callback_token, updated_at = EXCLUDED.updated_at,
+    state = 'pending', requested_at = NULL, lease_expires_at = NULL
+WHERE websub_subscriptions.hub_url <> EXCLUDED.hub_url OR websub_subscriptions.topic_url <> EXCLUDED.topic_url;
+
+-- name: GetWebSubSubscription :one
+SELECT * FROM websub_subscriptions
+WHERE feed_id = $1;
+
+-- name: GetWebSubSubscriptionsToRequest :many
+SELECT * FROM websub_subscriptions
+WHERE (requested_at IS NULL OR requested_at <= @retry_before::timestamp)
+AND (state = 'pending' OR (state = 'active' AND lease_expires_at <= @renew_before::timestamp))
+ORDER BY updated_at;
+
+-- name: MarkWebSubRequested :exec
+UPDATE websub_subscriptions
+SET requested_at = $1, updated_at = $1
+WHERE feed_id = $2;
+
+-- name: ActivateWebSubSubscription :execrows
+UPDATE websub_subscriptions
+SET state = 'active', lease_expires_at = $1, requested_at = NULL, updated_at = $2
+WHERE feed_id = $3 AND requested_at IS NOT NULL;
+
+-- name: DenyWebSubSubscription :execrows
+UPDATE websub_subscriptions
+SET state = 'denied', requested_at = NULL, updated_at = $1
+WHERE feed_id = $2 AND requested_at IS NOT NULL;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE user_saved_posts (
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	post_id uuid NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	note TEXT,
+	PRIMARY KEY (user_id, post_id)
+);
+
+-- +goose Down
+DROP TABLE user_saved_posts;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE alerts (
+	id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	query TEXT NOT NULL
+);
+CREATE TABLE alert_hits (
+	alert_id uuid NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+	post_id uuid NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (alert_id, post_id)
+);
+
+-- +goose Down
+DROP TABLE alert_hits;
+DROP TABLE alerts;
@@ -0,0 +1,37 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN author TEXT,
+ADD COLUMN categories TEXT;
+CREATE TABLE rules (
+	id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	feed_id uuid REFERENCES feeds(id) ON DELETE CASCADE,
+	field TEXT NOT NULL CHECK (field IN ('title', 'description', 'author', 'category')),
+	match_type TEXT NOT NULL CHECK (match_type IN ('substring', 'regex')),
+	pattern TEXT NOT NULL,
+	action TEXT NOT NULL CHECK (action IN ('hide', 'read', 'star', 'tag')),
+	tag TEXT
+);
+CREATE TABLE hidden_posts (
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	post_id uuid NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, post_id)
+);
+CREATE TABLE post_tags (
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	post_id uuid NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	tag TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, post_id, tag)
+);
+
+-- +goose Down
+DROP TABLE post_tags;
+DROP TABLE hidden_posts;
+DROP TABLE rules;
+ALTER TABLE posts
+DROP COLUMN author,
+DROP COLUMN categories;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE folders (
+	id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	CONSTRAINT folders_user_name_constraint UNIQUE (user_id, name)
+);
+ALTER TABLE feed_follows
+ADD COLUMN folder_id uuid REFERENCES folders(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE feed_follows
+DROP COLUMN folder_id;
+DROP TABLE folders;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feed_follows
+ADD COLUMN custom_name TEXT,
+ADD COLUMN priority INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN notify BOOLEAN NOT NULL DEFAULT TRUE,
+ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE feed_follows
+DROP COLUMN custom_name,
+DROP COLUMN priority,
+DROP COLUMN notify,
+DROP COLUMN hidden;
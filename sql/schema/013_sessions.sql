@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN password_hash TEXT;
+CREATE TABLE sessions (
+	token_hash TEXT PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE sessions;
+ALTER TABLE users
+DROP COLUMN password_hash;
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member'));
+UPDATE users
+SET role = 'admin'
+WHERE id = (SELECT id FROM users ORDER BY created_at LIMIT 1);
+CREATE TABLE role_changes (
+	id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	changed_by uuid REFERENCES users(id) ON DELETE SET NULL,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE role_changes;
+ALTER TABLE users
+DROP COLUMN role;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE audit_log (
+	id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	-- no foreign key, entries outlive deleted users
+	user_id uuid,
+	user_name TEXT,
+	command TEXT NOT NULL,
+	arguments TEXT NOT NULL,
+	outcome TEXT NOT NULL CHECK (outcome IN ('success', 'failure')),
+	error TEXT
+);
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at);
+
+-- +goose Down
+DROP TABLE audit_log;
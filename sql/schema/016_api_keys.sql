@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE api_keys (
+	id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	key_hash TEXT UNIQUE NOT NULL,
+	last_used_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE api_keys;
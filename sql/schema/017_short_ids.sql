@@ -0,0 +1,10 @@
+-- +goose Up
+-- numeric ids for clients which can't work with uuids, e.g. Fever API
+ALTER TABLE posts ADD COLUMN short_id BIGSERIAL UNIQUE NOT NULL;
+ALTER TABLE feeds ADD COLUMN short_id BIGSERIAL UNIQUE NOT NULL;
+ALTER TABLE folders ADD COLUMN short_id BIGSERIAL UNIQUE NOT NULL;
+
+-- +goose Down
+ALTER TABLE folders DROP COLUMN short_id;
+ALTER TABLE feeds DROP COLUMN short_id;
+ALTER TABLE posts DROP COLUMN short_id;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE fever_credentials (
+	user_id uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	api_key TEXT UNIQUE NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE fever_credentials;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE webhooks (
+	id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	url TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	feed_id uuid REFERENCES feeds(id) ON DELETE CASCADE,
+	folder_id uuid REFERENCES folders(id) ON DELETE CASCADE,
+	payload_template TEXT
+);
+CREATE TABLE webhook_deliveries (
+	id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	webhook_id uuid NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+	post_id uuid NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INT NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP NOT NULL,
+	response_code INT,
+	last_error TEXT,
+	UNIQUE (webhook_id, post_id)
+);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE digest_subscriptions (
+	user_id uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	email TEXT NOT NULL,
+	frequency TEXT NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+	last_sent_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE digest_subscriptions;
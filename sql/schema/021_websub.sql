@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE websub_subscriptions (
+	feed_id uuid PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	hub_url TEXT NOT NULL,
+	topic_url TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	state TEXT NOT NULL DEFAULT 'pending' CHECK (state IN ('pending', 'active', 'denied')),
+	requested_at TIMESTAMP,
+	lease_expires_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE websub_subscriptions;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE password_resets (
+	user_id uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	token_hash TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE password_resets;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE timeline_tokens (
+	user_id uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	token_hash TEXT UNIQUE NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE timeline_tokens;
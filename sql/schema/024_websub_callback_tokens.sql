@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE websub_subscriptions
+ADD COLUMN callback_token TEXT NOT NULL DEFAULT md5(gen_random_uuid()::text);
+ALTER TABLE websub_subscriptions
+ALTER COLUMN callback_token DROP DEFAULT;
+UPDATE websub_subscriptions
+SET state = 'pending', requested_at = NULL, lease_expires_at = NULL;
+
+-- +goose Down
+ALTER TABLE websub_subscriptions
+DROP COLUMN callback_token;
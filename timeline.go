@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"internal/database"
+	"internal/feedgen"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	timelineAtom = "atom"
+	timelineRSS = "rss"
+)
+
+// timelineSize is the number of newest posts re-published in user timeline feeds
+const timelineSize = 50
+
+func handlerTimelineToken(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 1 {
+		return usageError("timeline command expects a subcommand - rotate or disable")
+	}
+
+	switch cmd.arguments[0] {
+	case "rotate":
+		token, err := newToken()
+		if err != nil {
+			return err
+		}
+
+		// only the hash is kept, so urls with the previous token stop working
+		err = s.db.SetTimelineToken(context.Background(), database.SetTimelineTokenParams{
+			UserID: currentUser.ID,
+			TokenHash: hashToken(token),
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			slog.Error("error while saving timeline token", "error", err)
+
+			return err
+		}
+
+		fmt.Println("successfull timeline token rotation, subscribe to your timelines at")
+		fmt.Printf("/users/%s/feed.atom?token=%s\n", currentUser.Name, token)
+		fmt.Printf("/users/%s/folders/<folder>/feed.atom?token=%s\n", currentUser.Name, token)
+		fmt.Println("or feed.rss instead of feed.atom, relative to the url of gator server")
+	case "disable":
+		removed, err := s.db.RemoveTimelineToken(context.Background(), currentUser.ID)
+		if err != nil {
+			slog.Error("error while removing timeline token", "error", err)
+
+			return err
+		}
+
+		if removed == 0 {
+			return notFoundError("timeline is not enabled")
+		}
+
+		fmt.Println("successfull timeline disable")
+	default:
+		return usageError("no such timeline subcommand - %s", cmd.arguments[0])
+	}
+
+	return nil
+}
+
+// handlerTimeline re-publishes posts of all feeds followed by the user,
+// or only of feeds in one of the user's folders, as Atom or RSS feed.
+// Timelines are read by feed readers which can't log in, so they are
+// protected by the secret token of the user in the url instead
+func handlerTimeline(s *state, format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			respondWithError(w, r, notFoundError("no such timeline"))
+
+			return
+		}
+
+		user, err := s.db.GetUserByTimelineToken(r.Context(), hashToken(token))
+		// wrong token and wrong user look the same, so names of users can't be guessed
+		if err == sql.ErrNoRows || (err == nil && user.Name != r.PathValue("name")) {
+			respondWithError(w, r, notFoundError("no such timeline"))
+
+			return
+		}
+		if err != nil {
+			respondWithError(w, r, err)
+
+			return
+		}
+
+		title := fmt.Sprintf("%s's timeline", user.Name)
+
+		params := database.GetPostsForUserParams{
+			UserID: user.ID,
+			PostLimit: timelineSize,
+		}
+
+		if folderName := r.PathValue("folder"); folderName != "" {
+			_, err := s.db.GetFolderByName(r.Context(), database.GetFolderByNameParams{
+				UserID: user.ID,
+				Name: folderName,
+			})
+			if err == sql.ErrNoRows {
+				respondWithError(w, r, notFoundError("no such folder"))
+
+				return
+			}
+			if err != nil {
+				respondWithError(w, r, err)
+
+				return
+			}
+
+			title = fmt.Sprintf("%s's %s timeline", user.Name, folderName)
+			params.Folder = sql.NullString{ String: folderName, Valid: true }
+		}
+
+		posts, err := s.db.GetPostsForUser(r.Context(), params)
+		if err != nil {
+			respondWithError(w, r, err)
+
+			return
+		}
+
+		// id stays the same when the token is rotated
+		id := requestBaseURL(r) + r.URL.Path
+		selfLink := id + "?" + r.URL.RawQuery
+
+		feed := feedgen.Feed{
+			ID: id,
+			Title: title,
+			Subtitle: fmt.Sprintf("posts from feeds followed by %s", user.Name),
+			Author: user.Name,
+			SelfLink: selfLink,
+			Link: requestBaseURL(r),
+			Updated: user.CreatedAt,
+		}
+
+		for _, post := range posts {
+			if post.UpdatedAt.After(feed.Updated) {
+				feed.Updated = post.UpdatedAt
+			}
+
+			entry := feedgen.Entry{
+				ID: "urn:uuid:" + post.ID.String(),
+				Title: post.Title.String,
+				Link: post.Url,
+				Summary: post.Description.String,
+				Author: post.Author.String,
+				Published: post.PublishedAt,
+				Updated: post.UpdatedAt,
+			}
+
+			if post.Categories.Valid {
+				entry.Categories = strings.Split(post.Categories.String, ", ")
+			}
+
+			feed.Entries = append(feed.Entries, entry)
+		}
+
+		var body bytes.Buffer
+		contentType := "application/atom+xml; charset=utf-8"
+
+		if format == timelineRSS {
+			contentType = "application/rss+xml; charset=utf-8"
+			err = feed.WriteRSS(&body)
+		} else {
+			err = feed.WriteAtom(&body)
+		}
+		if err != nil {
+			respondWithError(w, r, err)
+
+			return
+		}
+
+		hash := sha256.Sum256(body.Bytes())
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "private, max-age=300")
+		w.Header().Set("ETag", fmt.Sprintf("\"%s\"", hex.EncodeToString(hash[:16])))
+
+		// ServeContent answers conditional requests with 304 using ETag and Last-Modified
+		http.ServeContent(w, r, "", feed.Updated, bytes.NewReader(body.Bytes()))
+	}
+}
+
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host
+}
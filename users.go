@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"internal/database"
+	"log/slog"
+	"time"
+)
+
+func handlerDeleteUser(s *state, cmd command, currentUser database.User) error {
+	flags := flag.NewFlagSet("deleteuser", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+
+	err := parseFlags(flags, cmd.arguments)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageError("there should be one argument for deleteuser command - user name")
+	}
+
+	user, err := getManagedUser(s, flags.Arg(0), currentUser)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == roleAdmin {
+		admins, err := s.db.CountAdmins(context.Background())
+		if err != nil {
+			slog.Error("error while counting admins", "error", err)
+
+			return err
+		}
+
+		if admins <= 1 {
+			return notAllowedError("the last admin can't be deleted")
+		}
+	}
+
+	// other users would lose feeds they follow along with their stars and notes
+	followedFeedsCount, err := s.db.GetFeedsFollowedByOthersCountForUser(context.Background(), user.ID)
+	if err != nil {
+		slog.Error("error while counting feeds followed by other users", "error", err)
+
+		return err
+	}
+
+	if followedFeedsCount > 0 {
+		return notAllowedError("%d feeds added by %s are followed by other users, transfer them with transferfeed first", followedFeedsCount, user.Name)
+	}
+
+	feedsCount, err := s.db.GetFeedsCountForUser(context.Background(), user.ID)
+	if err != nil {
+		slog.Error("error while counting feeds added by user", "error", err)
+
+		return err
+	}
+
+	userStats, err := s.db.GetUserDataStats(context.Background(), user.ID)
+	if err != nil {
+		slog.Error("error while counting user data", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("deleting %s will also remove %d feeds added by them with their posts, and %d follows, %d starred posts, %d alerts, %d rules and %d folders\n", user.Name, feedsCount, userStats.FollowsCount, userStats.SavedPostsCount, userStats.AlertsCount, userStats.RulesCount, userStats.FoldersCount)
+
+	if !*yes {
+		confirmed, err := confirm("delete the user?")
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Println("user is not deleted")
+
+			return nil
+		}
+	}
+
+	_, err = s.db.DeleteUser(context.Background(), user.ID)
+	if err != nil {
+		slog.Error("error while deleting user", "error", err)
+
+		return err
+	}
+
+	if user.ID != currentUser.ID {
+		fmt.Printf("successfull delete of %s\n", user.Name)
+
+		return nil
+	}
+
+	// sessions of the user are removed by cascade
+	err = s.cfg.SetSession("")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("successfull user delete, you are logged out")
+
+	return nil
+}
+
+func handlerRenameUser(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 2 {
+		return usageError("there should be two arguments for renameuser command - current user name and new user name")
+	}
+
+	user, err := getManagedUser(s, cmd.arguments[0], currentUser)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.RenameUser(context.Background(), database.RenameUserParams{
+		Name: cmd.arguments[1],
+		UpdatedAt: time.Now(),
+		ID: user.ID,
+	})
+	if err != nil {
+		slog.Error("error while renaming user", "error", err)
+
+		return err
+	}
+
+	fmt.Printf("successfully renamed %s to %s\n", cmd.arguments[0], cmd.arguments[1])
+
+	return nil
+}
+
+// getManagedUser returns the user if current user may change them - it is the user themselves or an admin
+func getManagedUser(s *state, userName string, currentUser database.User) (database.User, error) {
+	if userName == currentUser.Name {
+		return currentUser, nil
+	}
+
+	if currentUser.Role != roleAdmin {
+		return database.User{}, notAllowedError("only admins can change other users")
+	}
+
+	user, err := s.db.GetUserByName(context.Background(), userName)
+	if err != nil {
+		slog.Error("no such user", "name", userName, "error", err)
+
+		return database.User{}, err
+	}
+
+	return user, nil
+}
+
+func handlerWhoami(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) != 0 {
+		return usageError("there shouldn't be any arguments for whoami command")
+	}
+
+	feedsCount, err := s.db.GetFeedsCountForUser(context.Background(), currentUser.ID)
+	if err != nil {
+		slog.Error("error while counting feeds added by user", "error", err)
+
+		return err
+	}
+
+	userStats, err := s.db.GetUserDataStats(context.Background(), currentUser.ID)
+	if err != nil {
+		slog.Error("error while counting user data", "error", err)
+
+		return err
+	}
+
+	unread_counts, err := s.db.GetUnreadCountsForUser(context.Background(), currentUser.ID)
+	if err != nil {
+		slog.Error("error while retrieving unread counts for current user", "error", err)
+
+		return err
+	}
+
+	var unread int64
+	for _, count := range unread_counts {
+		unread += count.UnreadCount
+	}
+
+	fmt.Printf("Name: %s\n", currentUser.Name)
+	fmt.Printf("Role: %s\n", currentUser.Role)
+	fmt.Printf("Registered: %s\n", currentUser.CreatedAt.Format(time.DateTime))
+	fmt.Printf("Feeds added: %d\n", feedsCount)
+	fmt.Printf("Feeds followed: %d\n", userStats.FollowsCount)
+	fmt.Printf("Unread posts: %d\n", unread)
+	fmt.Printf("Starred posts: %d\n", userStats.SavedPostsCount)
+
+	return nil
+}
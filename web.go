@@ -0,0 +1,468 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"internal/database"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+const sessionCookie = "gator_session"
+
+// webPageSize is the number of posts shown on one page of the river
+const webPageSize = 30
+
+var (
+	loginTemplate = pageTemplate("login.html")
+	riverTemplate = pageTemplate("river.html")
+	subscriptionsTemplate = pageTemplate("subscriptions.html")
+)
+
+var tagsRegexp = regexp.MustCompile(`<[^>]*>`)
+
+type webPage struct {
+	Title string
+	User *database.User
+	Error string
+	Return string
+	Posts []database.GetPostsForUserRow
+	Follows []database.GetFeedFollowsForUserRow
+	NextPage string
+}
+
+func pageTemplate(name string) *template.Template {
+	funcs := template.FuncMap{ "summary": summary }
+
+	return template.Must(template.New(name).Funcs(funcs).ParseFS(templatesFS, "templates/layout.html", "templates/" + name))
+}
+
+// summary turns html description of a post into a short plain text
+func summary(description string) string {
+	text := html.UnescapeString(tagsRegexp.ReplaceAllString(description, " "))
+	text = strings.Join(strings.Fields(text), " ")
+
+	runes := []rune(text)
+	if len(runes) > 300 {
+		return string(runes[:300]) + "…"
+	}
+
+	return text
+}
+
+func render(w http.ResponseWriter, r *http.Request, status int, tmpl *template.Template, page webPage) {
+	// rendering into buffer first so that template errors don't leave half written pages
+	var body bytes.Buffer
+
+	err := tmpl.ExecuteTemplate(&body, "layout", page)
+	if err != nil {
+		webError(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(body.Bytes())
+}
+
+func webError(w http.ResponseWriter, r *http.Request, err error) {
+	status := httpStatus(err)
+	message := err.Error()
+
+	if status == http.StatusInternalServerError {
+		slog.Error("error while handling request", "method", r.Method, "path", r.URL.Path, "error", err)
+
+		message = "internal error"
+	}
+
+	http.Error(w, message, status)
+}
+
+// safeReturn keeps redirects after actions inside the web ui
+func safeReturn(value string) string {
+	if !strings.HasPrefix(value, "/web/") {
+		return "/web/"
+	}
+
+	return value
+}
+
+// middlewareWebSession authenticates requests by session cookie, the cookie is
+// SameSite=Lax so forms can't be submitted with it from other sites
+func middlewareWebSession(s *state, handler userHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil {
+			http.Redirect(w, r, "/web/login", http.StatusSeeOther)
+
+			return
+		}
+
+		user, err := s.db.GetUserBySession(r.Context(), database.GetUserBySessionParams{
+			TokenHash: hashToken(cookie.Value),
+			ExpiresAt: time.Now(),
+		})
+		if err == sql.ErrNoRows {
+			http.Redirect(w, r, "/web/login", http.StatusSeeOther)
+
+			return
+		}
+		if err != nil {
+			webError(w, r, err)
+
+			return
+		}
+
+		err = handler(s, w, r, user)
+		if err != nil {
+			webError(w, r, err)
+		}
+	}
+}
+
+func handlerWebLoginPage(s *state) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render(w, r, http.StatusOK, loginTemplate, webPage{ Title: "Login" })
+	}
+}
+
+func handlerWebLogin(s *state) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PostFormValue("name")
+
+		user, err := checkWebLogin(r.Context(), s, name, r.PostFormValue("password"))
+		if err != nil {
+			recordAudit(s, nil, r.Pattern, fmt.Sprintf("%q", name), err)
+
+			if errors.Is(err, errNotAllowed) {
+				// the reason is only in the audit log, so that it doesn't tell which users exist
+				render(w, r, http.StatusUnauthorized, loginTemplate, webPage{ Title: "Login", Error: "wrong user name or password" })
+
+				return
+			}
+
+			webError(w, r, err)
+
+			return
+		}
+
+		token, err := createSession(r.Context(), s, user)
+		recordAudit(s, &user, r.Pattern, fmt.Sprintf("%q", name), err)
+		if err != nil {
+			webError(w, r, err)
+
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name: sessionCookie,
+			Value: token,
+			Path: "/web/",
+			Expires: time.Now().Add(sessionDuration),
+			HttpOnly: true,
+			Secure: r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		http.Redirect(w, r, "/web/", http.StatusSeeOther)
+	}
+}
+
+// checkWebLogin returns the user with the given name and password, any wrong credentials are a not allowed error
+func checkWebLogin(ctx context.Context, s *state, name string, password string) (database.User, error) {
+	user, err := s.db.GetUserByName(ctx, name)
+	if err == sql.ErrNoRows {
+		return database.User{}, notAllowedError("no such user")
+	}
+	if err != nil {
+		return database.User{}, err
+	}
+
+	if !user.PasswordHash.Valid {
+		return database.User{}, notAllowedError("password isn't set")
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte(password))
+	if err != nil {
+		return database.User{}, notAllowedError("wrong password")
+	}
+
+	return user, nil
+}
+
+func webLogout(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	cookie, err := r.Cookie(sessionCookie)
+	if err == nil {
+		err = s.db.DeleteSession(r.Context(), hashToken(cookie.Value))
+		if err != nil {
+			return err
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{ Name: sessionCookie, Path: "/web/", MaxAge: -1 })
+	http.Redirect(w, r, "/web/login", http.StatusSeeOther)
+
+	return nil
+}
+
+// webRiver shows posts of all followed feeds, only unread ones or posts of one feed
+func webRiver(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	query := r.URL.Query()
+	feedURL := query.Get("feed")
+
+	offset, err := strconv.Atoi(query.Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	posts, err := s.db.GetPostsForUser(r.Context(), database.GetPostsForUserParams{
+		UserID: user.ID,
+		FeedUrl: sql.NullString{ String: feedURL, Valid: feedURL != "" },
+		UnreadOnly: query.Get("unread") == "true",
+		PostLimit: webPageSize,
+		PostOffset: int32(offset),
+	})
+	if err != nil {
+		return err
+	}
+
+	page := webPage{
+		Title: "River",
+		User: &user,
+		Return: r.URL.RequestURI(),
+		Posts: posts,
+	}
+
+	if query.Get("unread") == "true" {
+		page.Title = "Unread"
+	}
+
+	if feedURL != "" {
+		page.Title = feedURL
+		if len(posts) != 0 {
+			page.Title = posts[0].FeedName
+		}
+	}
+
+	if len(posts) == webPageSize {
+		next := url.Values{}
+		for key, values := range query {
+			next[key] = values
+		}
+
+		next.Set("offset", strconv.Itoa(offset + webPageSize))
+		page.NextPage = "/web/?" + next.Encode()
+	}
+
+	render(w, r, http.StatusOK, riverTemplate, page)
+
+	return nil
+}
+
+func webReadPost(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	postID, err := pathID(r, "postID")
+	if err != nil {
+		return err
+	}
+
+	post, err := s.db.GetPostByIdForUser(r.Context(), database.GetPostByIdForUserParams{
+		ID: postID,
+		UserID: user.ID,
+	})
+	if err == sql.ErrNoRows {
+		return notFoundError("no such post")
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		ReadAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, safeReturn(r.PostFormValue("return")), http.StatusSeeOther)
+
+	return nil
+}
+
+func webStarPost(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	postID, err := pathID(r, "postID")
+	if err != nil {
+		return err
+	}
+
+	post, err := s.db.GetPostByIdForUser(r.Context(), database.GetPostByIdForUserParams{
+		ID: postID,
+		UserID: user.ID,
+	})
+	if err == sql.ErrNoRows {
+		return notFoundError("no such post")
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.SavePost(r.Context(), database.SavePostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, safeReturn(r.PostFormValue("return")), http.StatusSeeOther)
+
+	return nil
+}
+
+func webUnstarPost(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	postID, err := pathID(r, "postID")
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.RemoveSavedPost(r.Context(), database.RemoveSavedPostParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, safeReturn(r.PostFormValue("return")), http.StatusSeeOther)
+
+	return nil
+}
+
+func webSubscriptions(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	return renderSubscriptions(s, w, r, user, http.StatusOK, "")
+}
+
+func renderSubscriptions(s *state, w http.ResponseWriter, r *http.Request, user database.User, status int, message string) error {
+	follows, err := s.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	render(w, r, status, subscriptionsTemplate, webPage{
+		Title: "Subscriptions",
+		User: &user,
+		Error: message,
+		Follows: follows,
+	})
+
+	return nil
+}
+
+func webFollow(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	feedURL := r.PostFormValue("url")
+
+	feed, err := s.db.GetFeedByURL(r.Context(), feedURL)
+	if err == sql.ErrNoRows {
+		return renderSubscriptions(s, w, r, user, http.StatusNotFound, "no feed with url " + feedURL + ", add it instead")
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID: uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if exitCode(err) == exitConflict {
+		return renderSubscriptions(s, w, r, user, http.StatusConflict, "you already follow " + feedURL)
+	}
+	if err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, "/web/subscriptions", http.StatusSeeOther)
+
+	return nil
+}
+
+func webAddFeed(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	name := r.PostFormValue("name")
+	feedURL := r.PostFormValue("url")
+
+	if name == "" || feedURL == "" {
+		return renderSubscriptions(s, w, r, user, http.StatusBadRequest, "both name and url of the feed are required")
+	}
+
+	feed, err := s.db.CreateFeed(r.Context(), database.CreateFeedParams{
+		ID: uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name: name,
+		Url: feedURL,
+		UserID: user.ID,
+	})
+	if exitCode(err) == exitConflict {
+		return renderSubscriptions(s, w, r, user, http.StatusConflict, "feed " + feedURL + " already exists, follow it instead")
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID: uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, "/web/subscriptions", http.StatusSeeOther)
+
+	return nil
+}
+
+func webUnfollow(s *state, w http.ResponseWriter, r *http.Request, user database.User) error {
+	feed, err := s.db.GetFeedByURL(r.Context(), r.PostFormValue("url"))
+	if err != nil {
+		return err
+	}
+
+	err = s.db.RemoveFeedFollowsForUser(r.Context(), database.RemoveFeedFollowsForUserParams{
+		FeedID: feed.ID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, "/web/subscriptions", http.StatusSeeOther)
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSummary(t *testing.T) {
+	long := strings.Repeat("ä", 301)
+
+	tests := []struct {
+		name string
+		description string
+		want string
+	}{
+		{ name: "plain text", description: "Hello world", want: "Hello world" },
+		{ name: "tags are stripped", description: "<p>Hello <b>world</b></p>", want: "Hello world" },
+		{ name: "tags separate words", description: "<p>Hello</p><p>world</p>", want: "Hello world" },
+		{ name: "whitespace is collapsed", description: "  Hello\n\n\t world  ", want: "Hello world" },
+		{ name: "entities are unescaped", description: "Tom &amp; Jerry &lt;3", want: "Tom & Jerry <3" },
+		{ name: "escaped tags stay text", description: "&lt;script&gt;", want: "<script>" },
+		{ name: "empty", description: "", want: "" },
+		{ name: "300 characters are kept", description: long[:600], want: long[:600] },
+		{ name: "longer is cut by characters", description: long, want: long[:600] + "…" },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := summary(test.description)
+			if got != test.want {
+				t.Errorf("summary(%q) = %q, want %q", test.description, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWebSessionRequired(t *testing.T) {
+	tests := []struct {
+		name string
+		cookie *http.Cookie
+	}{
+		{ name: "no cookie" },
+		{ name: "unknown session", cookie: &http.Cookie{ Name: sessionCookie, Value: "expired" } },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _ := newFakeState(t, map[string]fakeResult{
+				"GetUserBySession": {},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/web/", nil)
+			if test.cookie != nil {
+				req.AddCookie(test.cookie)
+			}
+
+			recorder := httptest.NewRecorder()
+			newServeMux(s).ServeHTTP(recorder, req)
+
+			if recorder.Code != http.StatusSeeOther || recorder.Header().Get("Location") != "/web/login" {
+				t.Errorf("response %d to %q, want redirect to login", recorder.Code, recorder.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestWebLoginFailure(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := func(passwordHash any) fakeResult {
+		return fakeResult{ rows: [][]driver.Value{ { uuid.New().String(), time.Now(), time.Now(), "alice", passwordHash, "member" } } }
+	}
+
+	tests := []struct {
+		name string
+		lookup fakeResult
+		password string
+		wantReason string
+	}{
+		{ name: "unknown user", lookup: fakeResult{}, password: "secret", wantReason: "no such user" },
+		{ name: "password isn't set", lookup: user(nil), password: "", wantReason: "password isn't set" },
+		{ name: "wrong password", lookup: user(string(hash)), password: "guess", wantReason: "wrong password" },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, db := newFakeState(t, map[string]fakeResult{
+				"GetUserByName": test.lookup,
+				"CreateAuditLogEntry": {},
+			})
+
+			form := url.Values{ "name": { "alice" }, "password": { test.password } }
+			req := httptest.NewRequest(http.MethodPost, "/web/login", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			recorder := httptest.NewRecorder()
+			newServeMux(s).ServeHTTP(recorder, req)
+
+			// every failure looks the same, so that it doesn't tell which users exist
+			if recorder.Code != http.StatusUnauthorized || !strings.Contains(recorder.Body.String(), "wrong user name or password") {
+				t.Errorf("response %d %q, want generic login error", recorder.Code, recorder.Body.String())
+			}
+
+			entries := db.called("CreateAuditLogEntry")
+			if len(entries) != 1 || entries[0][4] != "POST /web/login" || entries[0][6] != "failure" || !strings.HasSuffix(entries[0][7].(string), test.wantReason) {
+				t.Errorf("audit log entries %v, want failed login with reason %q", entries, test.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"internal/database"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	webhookPending = "pending"
+	webhookDelivered = "delivered"
+	webhookFailed = "failed"
+)
+
+// maxWebhookAttempts is the number of tries before delivery is given up,
+// the wait between tries doubles starting from a minute
+const maxWebhookAttempts = 5
+
+const webhookSignatureHeader = "X-Gator-Signature"
+
+// webhookClient checks every address it connects to, so webhooks can't reach
+// services on the machine gator runs on or cloud metadata endpoints, even
+// when the host name resolves differently than when the webhook was added
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{ Timeout: 10 * time.Second, Control: checkWebhookDial }).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
+type webhookPost struct {
+	ID uuid.UUID `json:"id"`
+	Title string `json:"title"`
+	URL string `json:"url"`
+	Description string `json:"description"`
+	Author string `json:"author,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	FeedName string `json:"feed_name"`
+	FeedURL string `json:"feed_url"`
+}
+
+type webhookPayload struct {
+	Event string `json:"event"`
+	WebhookID uuid.UUID `json:"webhook_id"`
+	Post webhookPost `json:"post"`
+}
+
+// newPayloadTemplate parses user defined payload, post fields are available
+// as {{.Title}}, {{.URL}} etc. and {{json .Title}} quotes them as json strings
+func newPayloadTemplate(text string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"json": func(value any) (string, error) {
+			encoded, err := json.Marshal(value)
+
+			return string(encoded), err
+		},
+	}
+
+	return template.New("payload").Funcs(funcs).Parse(text)
+}
+
+// webhookAddressAllowed tells if webhooks may be sent to the ip address,
+// loopback and link-local addresses are refused
+func webhookAddressAllowed(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsUnspecified()
+}
+
+func checkWebhookDial(network string, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !webhookAddressAllowed(ip) {
+		return fmt.Errorf("webhooks can't be sent to %s", host)
+	}
+
+	return nil
+}
+
+// validateWebhookURL rejects urls which aren't absolute http urls or point to
+// addresses webhooks can't be sent to
+func validateWebhookURL(rawURL string) (*url.URL, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, usageError("webhook url should be an absolute http or https url")
+	}
+
+	ips, err := net.LookupIP(target.Hostname())
+	if err != nil {
+		slog.Error("error while resolving webhook host", "host", target.Hostname(), "error", err)
+
+		return nil, fmt.Errorf("%w: %w", errUsage, err)
+	}
+
+	for _, ip := range ips {
+		if !webhookAddressAllowed(ip) {
+			return nil, usageError("webhook url can't point to loopback or link-local address %s", ip)
+		}
+	}
+
+	return target, nil
+}
+
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func handlerWebhook(s *state, cmd command, currentUser database.User) error {
+	if len(cmd.arguments) < 1 {
+		return usageError("webhook command expects a subcommand - add <url>, list, remove <webhook-id> or log <webhook-id>")
+	}
+
+	switch cmd.arguments[0] {
+	case "add":
+		flags := flag.NewFlagSet("webhook add", flag.ContinueOnError)
+		feedURL := flags.String("feed", "", "send only posts of the feed with this url")
+		folderName := flags.String("folder", "", "send only posts of feeds in this folder")
+		payload := flags.String("template", "", "payload template, default is json with post fields")
+
+		if len(cmd.arguments) < 2 {
+			return usageError("webhook add command expects url followed by --feed, --folder or --template")
+		}
+
+		err := parseFlags(flags, cmd.arguments[2:])
+		if err != nil {
+			return err
+		}
+
+		if flags.NArg() != 0 {
+			return usageError("webhook add command accepts only url, --feed, --folder and --template")
+		}
+
+		target, err := validateWebhookURL(cmd.arguments[1])
+		if err != nil {
+			return err
+		}
+
+		params := database.CreateWebhookParams{
+			ID: uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID: currentUser.ID,
+			Url: target.String(),
+			PayloadTemplate: sql.NullString{ String: *payload, Valid: *payload != "" },
+		}
+
+		if *payload != "" {
+			_, err := newPayloadTemplate(*payload)
+			if err != nil {
+				slog.Error("error while parsing --template", "error", err)
+
+				return fmt.Errorf("%w: %w", errUsage, err)
+			}
+		}
+
+		if *feedURL != "" {
+			feed, err := s.db.GetFeedByURL(context.Background(), *feedURL)
+			if err != nil {
+				slog.Error("error while retrieving feed for webhook", "error", err)
+
+				return err
+			}
+
+			params.FeedID = uuid.NullUUID{ UUID: feed.ID, Valid: true }
+		}
+
+		if *folderName != "" {
+			folder, err := s.db.GetFolderByName(context.Background(), database.GetFolderByNameParams{
+				UserID: currentUser.ID,
+				Name: *folderName,
+			})
+			if err != nil {
+				slog.Error("no such folder", "name", *folderName, "error", err)
+
+				return err
+			}
+
+			params.FolderID = uuid.NullUUID{ UUID: folder.ID, Valid: true }
+		}
+
+		params.Secret, err = newToken()
+		if err != nil {
+			return err
+		}
+
+		webhook, err := s.db.CreateWebhook(context.Background(), params)
+		if err != nil {
+			slog.Error("error while saving webhook", "error", err)
+
+			return err
+		}
+
+		fmt.Printf("successfully added webhook %s [%s]\n", webhook.Url, webhook.ID)
+		fmt.Printf("payloads are signed in %s header with secret:\n", webhookSignatureHeader)
+		fmt.Printf("\t%s\n", webhook.Secret)
+	case "list":
+		webhooks, err := s.db.GetWebhooksForUser(context.Background(), currentUser.ID)
+		if err != nil {
+			slog.Error("error while retrieving webhooks", "error", err)
+
+			return err
+		}
+
+		for _, webhook := range webhooks {
+			filter := "all followed feeds"
+			if webhook.FeedUrl.Valid {
+				filter = "feed " + webhook.FeedUrl.String
+			}
+			if webhook.FolderName.Valid {
+				filter += ", folder " + webhook.FolderName.String
+			}
+
+			fmt.Printf("* %s [%s] for %s\n", webhook.Url, webhook.ID, filter)
+			if webhook.PayloadTemplate.Valid {
+				fmt.Printf("\ttemplate: %s\n", webhook.PayloadTemplate.String)
+			}
+		}
+	case "remove":
+		if len(cmd.arguments) != 2 {
+			return usageError("there should be one argument for webhook remove command - id of the webhook")
+		}
+
+		webhookID, err := uuid.Parse(cmd.arguments[1])
+		if err != nil {
+			slog.Error("error while parsing argument as webhook id", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		removed, err := s.db.RemoveWebhook(context.Background(), database.RemoveWebhookParams{
+			ID: webhookID,
+			UserID: currentUser.ID,
+		})
+		if err != nil {
+			slog.Error("error while removing webhook", "error", err)
+
+			return err
+		}
+
+		if removed == 0 {
+			return notFoundError("no such webhook")
+		}
+
+		fmt.Println("successfull webhook remove")
+	case "log":
+		if len(cmd.arguments) != 2 {
+			return usageError("there should be one argument for webhook log command - id of the webhook")
+		}
+
+		webhookID, err := uuid.Parse(cmd.arguments[1])
+		if err != nil {
+			slog.Error("error while parsing argument as webhook id", "error", err)
+
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		deliveries, err := s.db.GetWebhookDeliveries(context.Background(), database.GetWebhookDeliveriesParams{
+			ID: webhookID,
+			UserID: currentUser.ID,
+			Limit: 20,
+		})
+		if err != nil {
+			slog.Error("error while retrieving webhook deliveries", "error", err)
+
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			fmt.Printf("[%s] %s after %d attempts, post \"%s\"\n", delivery.UpdatedAt.Format(time.DateTime), delivery.Status, delivery.Attempts, delivery.Title.String)
+
+			if delivery.ResponseCode.Valid {
+				fmt.Printf("\tresponse status %d\n", delivery.ResponseCode.Int32)
+			}
+			if delivery.LastError.Valid {
+				fmt.Printf("\terror: %s\n", delivery.LastError.String)
+			}
+			if delivery.Status == webhookPending {
+				fmt.Printf("\tnext attempt at %s\n", delivery.NextAttemptAt.Format(time.DateTime))
+			}
+		}
+	default:
+		return usageError("no such webhook subcommand - %s", cmd.arguments[0])
+	}
+
+	return nil
+}
+
+// deliverWebhooks sends deliveries which are due, failed ones are retried later
+// until maxWebhookAttempts is reached, on shutdown the delivery in flight is
+// still finished and recorded so that it isn't sent twice
+func deliverWebhooks(ctx context.Context, s *state) error {
+	deliveries, err := s.db.GetDueWebhookDeliveries(ctx, database.GetDueWebhookDeliveriesParams{
+		NextAttemptAt: time.Now(),
+		Limit: 50,
+	})
+	if err != nil {
+		slog.Error("error while retrieving webhook deliveries", "error", err)
+
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		statusCode, err := sendWebhook(delivery)
+
+		params := webhookDeliveryResult(delivery, statusCode, err, time.Now())
+
+		if err != nil {
+			slog.Warn("error while delivering webhook", "webhook_id", delivery.WebhookID, "delivery_id", delivery.ID, "url", delivery.WebhookUrl, "attempts", params.Attempts, "status", params.Status, "error", err)
+		}
+
+		err = s.db.UpdateWebhookDelivery(context.Background(), params)
+		if err != nil {
+			slog.Error("error while saving webhook delivery", "error", err)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// webhookDeliveryResult computes the new state of delivery after an attempt,
+// failed attempts are retried after a minute doubled with every attempt
+func webhookDeliveryResult(delivery database.GetDueWebhookDeliveriesRow, statusCode int, err error, now time.Time) database.UpdateWebhookDeliveryParams {
+	params := database.UpdateWebhookDeliveryParams{
+		Status: webhookDelivered,
+		Attempts: delivery.Attempts + 1,
+		NextAttemptAt: now,
+		UpdatedAt: now,
+		ID: delivery.ID,
+	}
+
+	if statusCode != 0 {
+		params.ResponseCode = sql.NullInt32{ Int32: int32(statusCode), Valid: true }
+	}
+
+	if err != nil {
+		params.LastError = sql.NullString{ String: err.Error(), Valid: true }
+		params.Status = webhookPending
+		params.NextAttemptAt = now.Add(time.Minute << delivery.Attempts)
+
+		if params.Attempts >= maxWebhookAttempts {
+			params.Status = webhookFailed
+		}
+	}
+
+	return params
+}
+
+func sendWebhook(delivery database.GetDueWebhookDeliveriesRow) (int, error) {
+	post := webhookPost{
+		ID: delivery.PostID,
+		Title: delivery.Title.String,
+		URL: delivery.PostUrl,
+		Description: delivery.Description.String,
+		Author: delivery.Author.String,
+		PublishedAt: delivery.PublishedAt,
+		FeedName: delivery.FeedName,
+		FeedURL: delivery.FeedUrl,
+	}
+
+	var body bytes.Buffer
+
+	if delivery.PayloadTemplate.Valid {
+		tmpl, err := newPayloadTemplate(delivery.PayloadTemplate.String)
+		if err != nil {
+			return 0, err
+		}
+
+		err = tmpl.Execute(&body, post)
+		if err != nil {
+			return 0, err
+		}
+
+		if !json.Valid(body.Bytes()) {
+			return 0, fmt.Errorf("payload template doesn't produce valid json")
+		}
+	} else {
+		err := json.NewEncoder(&body).Encode(webhookPayload{
+			Event: "post.created",
+			WebhookID: delivery.WebhookID,
+			Post: post,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.WebhookUrl, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gator")
+	req.Header.Set("X-Gator-Event", "post.created")
+	req.Header.Set("X-Gator-Delivery", delivery.ID.String())
+	req.Header.Set(webhookSignatureHeader, signWebhook(delivery.Secret, body.Bytes()))
+
+	res, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("receiver responded with %s", res.Status)
+	}
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"internal/database"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSignWebhook(t *testing.T) {
+	tests := []struct {
+		secret string
+		body string
+		want string
+	}{
+		{ secret: "secret", body: `{"event":"post.created"}`, want: "sha256=5bfab6fc075cfd13eb347eb022171d1fd85adce64716a0568bf15f9feb55c258" },
+		{ secret: "other", body: `{"event":"post.created"}`, want: "sha256=a732a6d1e4821efb6ab9d4ecd77d4339afa00df239e37f78cdf47fbb26c38764" },
+		{ secret: "secret", body: "", want: "sha256=f9e66e179b6747ae54108f82f8ade8b3c25d76fd30afde6c395822c530196169" },
+	}
+
+	for _, test := range tests {
+		got := signWebhook(test.secret, []byte(test.body))
+		if got != test.want {
+			t.Errorf("signWebhook(%q, %q) = %q, want %q", test.secret, test.body, got, test.want)
+		}
+	}
+}
+
+func testDelivery(url string) database.GetDueWebhookDeliveriesRow {
+	return database.GetDueWebhookDeliveriesRow{
+		ID: uuid.New(),
+		WebhookID: uuid.New(),
+		WebhookUrl: url,
+		Secret: "secret",
+		PostID: uuid.New(),
+		Title: sql.NullString{ String: "Hello", Valid: true },
+		PostUrl: "https://example.com/hello",
+		PublishedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		FeedName: "Example",
+		FeedUrl: "https://example.com/feed.xml",
+	}
+}
+
+// useWebhookClient lets tests deliver to httptest servers, which listen on loopback
+func useWebhookClient(t *testing.T, client *http.Client) {
+	previous := webhookClient
+	webhookClient = client
+
+	t.Cleanup(func() {
+		webhookClient = previous
+	})
+}
+
+func TestSendWebhookSignsPayload(t *testing.T) {
+	var received *http.Request
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	useWebhookClient(t, server.Client())
+
+	delivery := testDelivery(server.URL)
+
+	statusCode, err := sendWebhook(delivery)
+	if err != nil {
+		t.Fatalf("sendWebhook() error = %v", err)
+	}
+
+	if statusCode != http.StatusNoContent {
+		t.Errorf("sendWebhook() status = %d, want %d", statusCode, http.StatusNoContent)
+	}
+
+	if got, want := received.Header.Get(webhookSignatureHeader), signWebhook(delivery.Secret, body); got != want {
+		t.Errorf("%s header = %q, want %q", webhookSignatureHeader, got, want)
+	}
+
+	if got := received.Header.Get("X-Gator-Delivery"); got != delivery.ID.String() {
+		t.Errorf("X-Gator-Delivery header = %q, want %q", got, delivery.ID)
+	}
+
+	var payload webhookPayload
+
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		t.Fatalf("payload isn't json: %v", err)
+	}
+
+	if payload.Event != "post.created" || payload.WebhookID != delivery.WebhookID || payload.Post.Title != "Hello" {
+		t.Errorf("payload = %+v, want post.created of webhook %s with post Hello", payload, delivery.WebhookID)
+	}
+}
+
+func TestSendWebhookTemplate(t *testing.T) {
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	useWebhookClient(t, server.Client())
+
+	delivery := testDelivery(server.URL)
+	delivery.PayloadTemplate = sql.NullString{ String: `{"text": {{json .Title}}}`, Valid: true }
+
+	_, err := sendWebhook(delivery)
+	if err != nil {
+		t.Fatalf("sendWebhook() error = %v", err)
+	}
+
+	if string(body) != `{"text": "Hello"}` {
+		t.Errorf("body = %s, want templated payload", body)
+	}
+
+	delivery.PayloadTemplate = sql.NullString{ String: `{"text": {{.Title}}}`, Valid: true }
+
+	_, err = sendWebhook(delivery)
+	if err == nil {
+		t.Errorf("sendWebhook() with template producing invalid json succeeded")
+	}
+}
+
+func TestSendWebhookReceiverError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	useWebhookClient(t, server.Client())
+
+	statusCode, err := sendWebhook(testDelivery(server.URL))
+	if err == nil {
+		t.Errorf("sendWebhook() to failing receiver succeeded")
+	}
+
+	if statusCode != http.StatusServiceUnavailable {
+		t.Errorf("sendWebhook() status = %d, want %d", statusCode, http.StatusServiceUnavailable)
+	}
+}
+
+func TestSendWebhookRefusesLoopback(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	_, err := sendWebhook(testDelivery(server.URL))
+	if err == nil {
+		t.Errorf("sendWebhook() to %s succeeded", server.URL)
+	}
+
+	if requests != 0 {
+		t.Errorf("loopback receiver got %d requests, want none", requests)
+	}
+}
+
+func TestWebhookDeliveryResult(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	failure := errors.New("receiver responded with 500 Internal Server Error")
+
+	tests := []struct {
+		name string
+		attempts int32
+		statusCode int
+		err error
+		wantStatus string
+		wantAttempts int32
+		wantNext time.Time
+	}{
+		{ name: "delivered", attempts: 0, statusCode: 200, wantStatus: webhookDelivered, wantAttempts: 1, wantNext: now },
+		{ name: "delivered on retry", attempts: 2, statusCode: 204, wantStatus: webhookDelivered, wantAttempts: 3, wantNext: now },
+		{ name: "first failure", attempts: 0, statusCode: 500, err: failure, wantStatus: webhookPending, wantAttempts: 1, wantNext: now.Add(time.Minute) },
+		{ name: "backoff doubles", attempts: 3, statusCode: 500, err: failure, wantStatus: webhookPending, wantAttempts: 4, wantNext: now.Add(8 * time.Minute) },
+		{ name: "no response", attempts: 1, err: errors.New("connection refused"), wantStatus: webhookPending, wantAttempts: 2, wantNext: now.Add(2 * time.Minute) },
+		{ name: "last attempt", attempts: maxWebhookAttempts - 1, statusCode: 500, err: failure, wantStatus: webhookFailed, wantAttempts: maxWebhookAttempts, wantNext: now.Add(16 * time.Minute) },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			delivery := testDelivery("https://example.com/hook")
+			delivery.Attempts = test.attempts
+
+			params := webhookDeliveryResult(delivery, test.statusCode, test.err, now)
+
+			if params.Status != test.wantStatus {
+				t.Errorf("status = %s, want %s", params.Status, test.wantStatus)
+			}
+
+			if params.Attempts != test.wantAttempts {
+				t.Errorf("attempts = %d, want %d", params.Attempts, test.wantAttempts)
+			}
+
+			if !params.NextAttemptAt.Equal(test.wantNext) {
+				t.Errorf("next attempt at %s, want %s", params.NextAttemptAt, test.wantNext)
+			}
+
+			if params.ResponseCode.Valid != (test.statusCode != 0) || int(params.ResponseCode.Int32) != test.statusCode {
+				t.Errorf("response code = %+v, want %d", params.ResponseCode, test.statusCode)
+			}
+
+			if params.LastError.Valid != (test.err != nil) {
+				t.Errorf("last error = %+v, want error %v", params.LastError, test.err)
+			}
+
+			if params.ID != delivery.ID {
+				t.Errorf("id = %s, want %s", params.ID, delivery.ID)
+			}
+		})
+	}
+}
+
+func TestWebhookAddressAllowed(t *testing.T) {
+	tests := []struct {
+		ip string
+		want bool
+	}{
+		{ ip: "127.0.0.1", want: false },
+		{ ip: "127.10.0.1", want: false },
+		{ ip: "::1", want: false },
+		{ ip: "::ffff:127.0.0.1", want: false },
+		{ ip: "169.254.169.254", want: false },
+		{ ip: "fe80::1", want: false },
+		{ ip: "0.0.0.0", want: false },
+		{ ip: "::", want: false },
+		{ ip: "93.184.216.34", want: true },
+		{ ip: "10.0.0.1", want: true },
+		{ ip: "2606:4700::1111", want: true },
+	}
+
+	for _, test := range tests {
+		got := webhookAddressAllowed(net.ParseIP(test.ip))
+		if got != test.want {
+			t.Errorf("webhookAddressAllowed(%s) = %t, want %t", test.ip, got, test.want)
+		}
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url string
+		wantErr bool
+	}{
+		{ url: "https://93.184.216.34/hook", wantErr: false },
+		{ url: "ftp://93.184.216.34/hook", wantErr: true },
+		{ url: "/hook", wantErr: true },
+		{ url: "http://127.0.0.1:8080/hook", wantErr: true },
+		{ url: "http://[::1]/hook", wantErr: true },
+		{ url: "http://169.254.169.254/latest/meta-data", wantErr: true },
+		{ url: "http://localhost/hook", wantErr: true },
+	}
+
+	for _, test := range tests {
+		_, err := validateWebhookURL(test.url)
+		if (err != nil) != test.wantErr {
+			t.Errorf("validateWebhookURL(%q) error = %v, want error %t", test.url, err, test.wantErr)
+		}
+
+		if err != nil && !errors.Is(err, errUsage) {
+			t.Errorf("validateWebhookURL(%q) error = %v, want usage error", test.url, err)
+		}
+	}
+}
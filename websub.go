@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"internal/database"
+	"internal/rss"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// leases are asked for ten days and renewed a day before they expire,
+	// hubs granting shorter leases are simply asked more often
+	websubLease = 10 * 24 * time.Hour
+	websubRenewBefore = 24 * time.Hour
+	// requests not verified by the hub in this time are sent again
+	websubRetry = 10 * time.Minute
+	websubMaxContent = 10 << 20
+)
+
+const websubSignatureHeader = "X-Hub-Signature"
+
+var websubClient = &http.Client{ Timeout: 30 * time.Second }
+
+var websubHashes = map[string]func() hash.Hash{
+	"sha1": sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// setWebSubHub remembers the hub advertised by fetched feed, the subscription
+// itself is requested by the server as hubs need a public callback url
+func setWebSubHub(ctx context.Context, s *state, feed database.Feed, fetched *rss.RSSFeed) error {
+	topic := fetched.Self
+	if topic == "" {
+		topic = feed.Url
+	}
+
+	secret, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	callbackToken, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.db.SetWebSubHub(ctx, database.SetWebSubHubParams{
+		FeedID: feed.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		HubUrl: fetched.Hub,
+		TopicUrl: topic,
+		Secret: secret,
+		CallbackToken: callbackToken,
+	})
+	if err != nil {
+		slog.Error("error while saving websub hub of the feed", "feed_id", feed.ID, "url", feed.Url, "hub", fetched.Hub, "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// runWebSub keeps subscriptions requested and renewed while the server is running
+func runWebSub(s *state, publicURL string) {
+	ticker := time.NewTicker(time.Minute)
+	for ; ; <-ticker.C {
+		err := requestWebSubSubscriptions(s, publicURL)
+		if err != nil {
+			slog.Error("error while requesting websub subscriptions", "error", err)
+		}
+	}
+}
+
+func requestWebSubSubscriptions(s *state, publicURL string) error {
+	subscriptions, err := s.db.GetWebSubSubscriptionsToRequest(context.Background(), database.GetWebSubSubscriptionsToRequestParams{
+		RetryBefore: time.Now().Add(-websubRetry),
+		RenewBefore: time.Now().Add(websubRenewBefore),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		// the token keeps anyone but the hub from calling back about the subscription
+		callback := publicURL + "/websub/" + subscription.FeedID.String() + "/" + subscription.CallbackToken
+
+		// marked before asking, as hubs may verify the intent before responding, and failed
+		// requests stay marked, so they are retried after websubRetry
+		err = s.db.MarkWebSubRequested(context.Background(), database.MarkWebSubRequestedParams{
+			RequestedAt: sql.NullTime{ Time: time.Now(), Valid: true },
+			FeedID: subscription.FeedID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = requestWebSubSubscription(subscription, callback)
+		if err != nil {
+			slog.Error("error while subscribing to websub hub", "feed_id", subscription.FeedID, "url", subscription.TopicUrl, "hub", subscription.HubUrl, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func requestWebSubSubscription(subscription database.WebsubSubscription, callback string) error {
+	form := url.Values{
+		"hub.mode": { "subscribe" },
+		"hub.topic": { subscription.TopicUrl },
+		"hub.callback": { callback },
+		"hub.secret": { subscription.Secret },
+		"hub.lease_seconds": { strconv.Itoa(int(websubLease.Seconds())) },
+	}
+
+	req, err := http.NewRequest(http.MethodPost, subscription.HubUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "gator")
+
+	res, err := websubClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("hub responded with %s", res.Status)
+	}
+
+	return nil
+}
+
+func validWebSubSignature(secret string, header string, body []byte) bool {
+	method, signature, found := strings.Cut(header, "=")
+	if !found {
+		return false
+	}
+
+	newHash, known := websubHashes[method]
+	if !known {
+		return false
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+
+	return hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(strings.ToLower(signature)))
+}
+
+func validWebSubCallbackToken(subscription database.WebsubSubscription, token string) bool {
+	return subscription.CallbackToken != "" && subtle.ConstantTimeCompare([]byte(subscription.CallbackToken), []byte(token)) == 1
+}
+
+// webSubLease clamps the lease granted by the hub, longer leases are renewed as if
+// websubLease was granted
+func webSubLease(leaseSeconds string) (time.Duration, error) {
+	seconds, err := strconv.Atoi(leaseSeconds)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("hub.lease_seconds should be a positive number")
+	}
+
+	if seconds > int(websubLease.Seconds()) {
+		return websubLease, nil
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// handlerWebSubVerify answers intent verification of the hub, confirming only
+// the subscription request gator has outstanding, which is always a subscribe
+func handlerWebSubVerify(s *state) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		feedID, err := pathID(r, "feedID")
+		if err != nil {
+			webError(w, r, err)
+
+			return
+		}
+
+		query := r.URL.Query()
+		challenge := query.Get("hub.challenge")
+
+		subscription, err := s.db.GetWebSubSubscription(r.Context(), feedID)
+		if err == sql.ErrNoRows {
+			// the feed was removed, so only unsubscribing from it is confirmed
+			if query.Get("hub.mode") == "unsubscribe" {
+				io.WriteString(w, challenge)
+
+				return
+			}
+
+			http.NotFound(w, r)
+
+			return
+		}
+		if err != nil {
+			webError(w, r, err)
+
+			return
+		}
+
+		if !validWebSubCallbackToken(subscription, r.PathValue("token")) {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if !subscription.RequestedAt.Valid || query.Get("hub.topic") != subscription.TopicUrl {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		switch query.Get("hub.mode") {
+		case "subscribe":
+			lease, err := webSubLease(query.Get("hub.lease_seconds"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+
+				return
+			}
+
+			leaseExpiresAt := time.Now().Add(lease)
+
+			// the request is answered only once, a repeated verification finds nothing to confirm
+			activated, err := s.db.ActivateWebSubSubscription(r.Context(), database.ActivateWebSubSubscriptionParams{
+				LeaseExpiresAt: sql.NullTime{ Time: leaseExpiresAt, Valid: true },
+				UpdatedAt: time.Now(),
+				FeedID: feedID,
+			})
+			if err != nil {
+				webError(w, r, err)
+
+				return
+			}
+
+			if activated == 0 {
+				http.NotFound(w, r)
+
+				return
+			}
+
+			slog.Info("subscribed to websub hub", "feed_id", feedID, "url", subscription.TopicUrl, "hub", subscription.HubUrl, "lease_expires_at", leaseExpiresAt)
+
+			io.WriteString(w, challenge)
+		case "denied":
+			denied, err := s.db.DenyWebSubSubscription(r.Context(), database.DenyWebSubSubscriptionParams{
+				UpdatedAt: time.Now(),
+				FeedID: feedID,
+			})
+			if err != nil {
+				webError(w, r, err)
+
+				return
+			}
+
+			if denied == 0 {
+				http.NotFound(w, r)
+
+				return
+			}
+
+			slog.Warn("websub hub denied subscription", "feed_id", feedID, "url", subscription.TopicUrl, "hub", subscription.HubUrl, "reason", query.Get("hub.reason"))
+		default:
+			// gator never unsubscribes from feeds it still has
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// handlerWebSubContent ingests content distributed by the hub through the same
+// pipeline as polled feeds
+func handlerWebSubContent(s *state) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		feedID, err := pathID(r, "feedID")
+		if err != nil {
+			webError(w, r, err)
+
+			return
+		}
+
+		subscription, err := s.db.GetWebSubSubscription(r.Context(), feedID)
+		if err == sql.ErrNoRows {
+			// gone tells the hub to stop distributing content of removed feeds
+			http.Error(w, "no such subscription", http.StatusGone)
+
+			return
+		}
+		if err != nil {
+			webError(w, r, err)
+
+			return
+		}
+
+		if !validWebSubCallbackToken(subscription, r.PathValue("token")) {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, websubMaxContent))
+		if err != nil {
+			http.Error(w, "content is too large", http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		// content with wrong signature is acknowledged but ignored, as WebSub requires
+		if !validWebSubSignature(subscription.Secret, r.Header.Get(websubSignatureHeader), body) {
+			slog.Warn("ignoring websub content with invalid signature", "feed_id", feedID, "url", subscription.TopicUrl)
+
+			w.WriteHeader(http.StatusAccepted)
+
+			return
+		}
+
+		feed, err := rss.ParseFeed(body)
+		if err != nil {
+			feedParseErrors.Inc()
+
+			slog.Error("error while parsing websub content", "feed_id", feedID, "url", subscription.TopicUrl, "error", err)
+
+			http.Error(w, "content is not a valid rss feed", http.StatusBadRequest)
+
+			return
+		}
+
+		err = savePosts(r.Context(), s, feedID, feed.Channel.Item)
+		if err != nil {
+			webError(w, r, err)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestValidWebSubSignature(t *testing.T) {
+	body := []byte("<rss/>")
+
+	tests := []struct {
+		name string
+		header string
+		want bool
+	}{
+		{ name: "sha1", header: "sha1=964b65eb9a12e7d992ed0309dd6653592fe62735", want: true },
+		{ name: "sha256", header: "sha256=5f74509bd137b5135e73bb30f53d1fe7fd111e07cf39bd57970d148c90bb1f2a", want: true },
+		{ name: "sha512", header: "sha512=bf379f479c87541207c397e7ceffc63852d5fd0f63dc877b926ac65e100da0f94300fa3a77ee88ab85ef5869e0ab3bceb925e2e3767ce73ef199c38abdb86cb7", want: true },
+		{ name: "uppercase hex", header: "sha256=5F74509BD137B5135E73BB30F53D1FE7FD111E07CF39BD57970D148C90BB1F2A", want: true },
+		{ name: "wrong signature", header: "sha256=0f74509bd137b5135e73bb30f53d1fe7fd111e07cf39bd57970d148c90bb1f2a", want: false },
+		{ name: "signature of other method", header: "sha1=5f74509bd137b5135e73bb30f53d1fe7fd111e07cf39bd57970d148c90bb1f2a", want: false },
+		{ name: "unknown method", header: "md5=0b8b2cd9b7d6e4ae1e4e1bd64c0e3e14", want: false },
+		{ name: "no method", header: "5f74509bd137b5135e73bb30f53d1fe7fd111e07cf39bd57970d148c90bb1f2a", want: false },
+		{ name: "missing", header: "", want: false },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validWebSubSignature("secret", test.header, body)
+			if got != test.want {
+				t.Errorf("validWebSubSignature(%q) = %t, want %t", test.header, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWebSubLease(t *testing.T) {
+	tests := []struct {
+		leaseSeconds string
+		want time.Duration
+		wantErr bool
+	}{
+		{ leaseSeconds: "86400", want: 24 * time.Hour },
+		{ leaseSeconds: "864000", want: websubLease },
+		{ leaseSeconds: "315360000", want: websubLease },
+		{ leaseSeconds: "99999999999999999", want: websubLease },
+		{ leaseSeconds: "0", wantErr: true },
+		{ leaseSeconds: "-60", wantErr: true },
+		{ leaseSeconds: "", wantErr: true },
+		{ leaseSeconds: "forever", wantErr: true },
+	}
+
+	for _, test := range tests {
+		got, err := webSubLease(test.leaseSeconds)
+		if (err != nil) != test.wantErr {
+			t.Errorf("webSubLease(%q) error = %v, want error %t", test.leaseSeconds, err, test.wantErr)
+		}
+
+		if got != test.want {
+			t.Errorf("webSubLease(%q) = %s, want %s", test.leaseSeconds, got, test.want)
+		}
+	}
+}
+
+const (
+	testWebSubTopic = "https://example.com/feed.xml"
+	testWebSubToken = "callbacktoken"
+)
+
+// testWebSubSubscription returns row of websub_subscriptions, requestedAt is nil when no request is outstanding
+func testWebSubSubscription(feedID uuid.UUID, requestedAt any) []driver.Value {
+	now := time.Now()
+
+	return []driver.Value{ feedID.String(), now, now, "https://hub.example.com/", testWebSubTopic, "secret", "pending", requestedAt, nil, testWebSubToken }
+}
+
+func TestWebSubVerify(t *testing.T) {
+	feedID := uuid.New()
+	requested := testWebSubSubscription(feedID, time.Now())
+	notRequested := testWebSubSubscription(feedID, nil)
+
+	tests := []struct {
+		name string
+		subscription []driver.Value
+		token string
+		query url.Values
+		updated int64
+		wantStatus int
+		wantBody string
+		wantQuery string
+	}{
+		{
+			name: "subscribe",
+			subscription: requested,
+			token: testWebSubToken,
+			query: url.Values{ "hub.mode": { "subscribe" }, "hub.topic": { testWebSubTopic }, "hub.challenge": { "abc" }, "hub.lease_seconds": { "86400" } },
+			updated: 1,
+			wantStatus: http.StatusOK,
+			wantBody: "abc",
+			wantQuery: "ActivateWebSubSubscription",
+		},
+		{
+			name: "subscribe with wrong token",
+			subscription: requested,
+			token: "guessed",
+			query: url.Values{ "hub.mode": { "subscribe" }, "hub.topic": { testWebSubTopic }, "hub.challenge": { "abc" }, "hub.lease_seconds": { "86400" } },
+			updated: 1,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "subscribe without outstanding request",
+			subscription: notRequested,
+			token: testWebSubToken,
+			query: url.Values{ "hub.mode": { "subscribe" }, "hub.topic": { testWebSubTopic }, "hub.challenge": { "abc" }, "hub.lease_seconds": { "86400" } },
+			updated: 1,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "subscribe to other topic",
+			subscription: requested,
+			token: testWebSubToken,
+			query: url.Values{ "hub.mode": { "subscribe" }, "hub.topic": { "https://example.com/other.xml" }, "hub.challenge": { "abc" }, "hub.lease_seconds": { "86400" } },
+			updated: 1,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "subscribe answered already",
+			subscription: requested,
+			token: testWebSubToken,
+			query: url.Values{ "hub.mode": { "subscribe" }, "hub.topic": { testWebSubTopic }, "hub.challenge": { "abc" }, "hub.lease_seconds": { "86400" } },
+			updated: 0,
+			wantStatus: http.StatusNotFound,
+			wantQuery: "ActivateWebSubSubscription",
+		},
+		{
+			name: "subscribe without lease",
+			subscription: requested,
+			token: testWebSubToken,
+			query: url.Values{ "hub.mode": { "subscribe" }, "hub.topic": { testWebSubTopic }, "hub.challenge": { "abc" } },
+			updated: 1,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "unsubscribe",
+			subscription: requested,
+			token: testWebSubToken,
+			query: url.Values{ "hub.mode": { "unsubscribe" }, "hub.topic": { testWebSubTopic }, "hub.challenge": { "abc" } },
+			updated: 1,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "denied",
+			subscription: requested,
+			token: testWebSubToken,
+			query: url.Values{ "hub.mode": { "denied" }, "hub.topic": { testWebSubTopic }, "hub.reason": { "no" } },
+			updated: 1,
+			wantStatus: http.StatusOK,
+			wantQuery: "DenyWebSubSubscription",
+		},
+		{
+			name: "denied with wrong token",
+			subscription: requested,
+			token: "guessed",
+			query: url.Values{ "hub.mode": { "denied" }, "hub.topic": { testWebSubTopic } },
+			updated: 1,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "denied without outstanding request",
+			subscription: notRequested,
+			token: testWebSubToken,
+			query: url.Values{ "hub.mode": { "denied" }, "hub.topic": { testWebSubTopic } },
+			updated: 1,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, db := newFakeState(t, map[string]fakeResult{
+				"GetWebSubSubscription": { rows: [][]driver.Value{ test.subscription } },
+				"ActivateWebSubSubscription": { rowsAffected: test.updated },
+				"DenyWebSubSubscription": { rowsAffected: test.updated },
+			})
+
+			target := "/websub/" + feedID.String() + "/" + test.token + "?" + test.query.Encode()
+
+			recorder := httptest.NewRecorder()
+			newServeMux(s).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+
+			// only subscriptions are confirmed by echoing the challenge
+			if test.wantStatus == http.StatusOK && recorder.Body.String() != test.wantBody {
+				t.Errorf("body = %q, want %q", recorder.Body.String(), test.wantBody)
+			}
+
+			for _, name := range []string{ "ActivateWebSubSubscription", "DenyWebSubSubscription" } {
+				calls := len(db.called(name))
+				if (name == test.wantQuery) != (calls == 1) {
+					t.Errorf("%s called %d times", name, calls)
+				}
+			}
+		})
+	}
+}
+
+func TestWebSubVerifyClampsLease(t *testing.T) {
+	feedID := uuid.New()
+
+	s, db := newFakeState(t, map[string]fakeResult{
+		"GetWebSubSubscription": { rows: [][]driver.Value{ testWebSubSubscription(feedID, time.Now()) } },
+		"ActivateWebSubSubscription": { rowsAffected: 1 },
+	})
+
+	query := url.Values{ "hub.mode": { "subscribe" }, "hub.topic": { testWebSubTopic }, "hub.challenge": { "abc" }, "hub.lease_seconds": { "315360000" } }
+	target := "/websub/" + feedID.String() + "/" + testWebSubToken + "?" + query.Encode()
+
+	newServeMux(s).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+
+	calls := db.called("ActivateWebSubSubscription")
+	if len(calls) != 1 {
+		t.Fatalf("ActivateWebSubSubscription called %d times, want once", len(calls))
+	}
+
+	leaseExpiresAt, ok := calls[0][0].(time.Time)
+	if !ok || leaseExpiresAt.After(time.Now().Add(websubLease)) {
+		t.Errorf("lease expires at %v, want at most %s from now", calls[0][0], websubLease)
+	}
+}
+
+func TestWebSubContentRequiresToken(t *testing.T) {
+	feedID := uuid.New()
+
+	tests := []struct {
+		name string
+		token string
+		wantStatus int
+	}{
+		{ name: "wrong token", token: "guessed", wantStatus: http.StatusNotFound },
+		// invalid signature is acknowledged without saving anything
+		{ name: "invalid signature", token: testWebSubToken, wantStatus: http.StatusAccepted },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _ := newFakeState(t, map[string]fakeResult{
+				"GetWebSubSubscription": { rows: [][]driver.Value{ testWebSubSubscription(feedID, nil) } },
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/websub/" + feedID.String() + "/" + test.token, strings.NewReader("<rss/>"))
+			req.Header.Set(websubSignatureHeader, "sha256=00")
+
+			recorder := httptest.NewRecorder()
+			newServeMux(s).ServeHTTP(recorder, req)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+		})
+	}
+}